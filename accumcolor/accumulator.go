@@ -0,0 +1,109 @@
+// This file defines the Accumulator interface, which all accumulating color
+// types implement.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+)
+
+// An Accumulator is a color.Color that supports accumulation of colors.  Code
+// written in terms of Accumulators, such as resamplers and stackers, works
+// with every accumulating color type in this package.  All methods that
+// modify an Accumulator have pointer receivers, so it is pointers to the
+// types in this package, such as *NRGBA and *LabA, that implement the
+// interface.
+type Accumulator interface {
+	color.Color
+
+	// Valid returns true if and only if the accumulated color satisfies
+	// the invariants of its type.
+	Valid() bool
+
+	// Add accumulates a color of any type.
+	Add(clr color.Color)
+
+	// ScaleWeight multiplies the weight of the accumulated color by w
+	// without changing the effective color.  It returns ErrWeight,
+	// leaving the color unchanged, if w is negative, NaN, or infinite
+	// or if the type maintains an integer tally and w is not an
+	// integer.
+	ScaleWeight(w float64) error
+
+	// Weight returns the total weight of all colors accumulated so far,
+	// which is normally the type's Tally field.
+	Weight() float64
+
+	// AverageColor returns the effective color of the accumulation as an
+	// ordinary, non-accumulating color.  The result is the zero color if
+	// Weight returns zero.
+	AverageColor() color.Color
+
+	// Merge accumulates everything accumulated by another Accumulator.
+	// Merging an Accumulator of the same type is exact.  An Accumulator
+	// of any other type is merged as its average color weighted by its
+	// total weight.  Merge returns ErrWeight, leaving the receiver
+	// unchanged, if the receiver cannot represent that weight, as when
+	// merging a fractional weight into a type with an integer tally.
+	Merge(other Accumulator) error
+}
+
+// Ensure at compile time that every accumulating color type implements
+// Accumulator.
+var (
+	_ Accumulator = (*NRGBA)(nil)
+	_ Accumulator = (*NRGBA64)(nil)
+	_ Accumulator = (*Gray)(nil)
+	_ Accumulator = (*Gray16)(nil)
+	_ Accumulator = (*LinearNRGBA)(nil)
+	_ Accumulator = (*RGBA64)(nil)
+	_ Accumulator = (*LabA)(nil)
+	_ Accumulator = (*CompensatedLabA)(nil)
+	_ Accumulator = (*OkLabA)(nil)
+	_ Accumulator = (*LChA)(nil)
+	_ Accumulator = (*HSVA)(nil)
+	_ Accumulator = (*HSLA)(nil)
+	_ Accumulator = (*WeightedNRGBA)(nil)
+	_ Accumulator = (*WeightedLabA)(nil)
+	_ Accumulator = (*NRGBAStats)(nil)
+	_ Accumulator = (*LabAStats)(nil)
+	_ Accumulator = (*MinNRGBA)(nil)
+	_ Accumulator = (*MaxNRGBA)(nil)
+)
+
+// scaleInteger implements ScaleWeight for a type with an integer tally.
+func scaleInteger(c interface{ Scale(w uint64) }, w float64) error {
+	if !(w >= 0.0 && w < 1<<64) || w != math.Trunc(w) {
+		return ErrWeight
+	}
+	c.Scale(uint64(w))
+	return nil
+}
+
+// scaleReal implements ScaleWeight for a type with a floating-point tally.
+func scaleReal(c interface{ Scale(w float64) }, w float64) error {
+	if !(w >= 0.0) || math.IsInf(w, 1) {
+		return ErrWeight
+	}
+	c.Scale(w)
+	return nil
+}
+
+// merge implements Merge for an accumulating color type C to which model
+// converts colors.
+func merge[C color.Color, P interface {
+	*C
+	Accumulator
+}](c P, model color.Model, other Accumulator) error {
+	if o, ok := other.(P); ok {
+		c.Add(*o)
+		return nil
+	}
+	clr := model.Convert(other.AverageColor()).(C)
+	if err := P(&clr).ScaleWeight(other.Weight()); err != nil {
+		return err
+	}
+	c.Add(clr)
+	return nil
+}
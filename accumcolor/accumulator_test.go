@@ -0,0 +1,156 @@
+// This file defines a suite of tests for the Accumulator interface.
+
+package accumcolor
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// newAccumulators returns a fresh, zero-valued instance of every accumulating
+// color type.
+func newAccumulators() []Accumulator {
+	return []Accumulator{
+		&NRGBA{},
+		&NRGBA64{},
+		&Gray{},
+		&Gray16{},
+		&LinearNRGBA{},
+		&RGBA64{},
+		&LabA{},
+		&CompensatedLabA{},
+		&OkLabA{},
+		&LChA{},
+		&HSVA{},
+		&HSLA{},
+		&WeightedNRGBA{},
+		&WeightedLabA{},
+		&NRGBAStats{},
+		&LabAStats{},
+		&MinNRGBA{},
+		&MaxNRGBA{},
+	}
+}
+
+// TestAccumulator exercises each Accumulator method on every accumulating
+// color type.
+func TestAccumulator(t *testing.T) {
+	c1 := color.NRGBA{R: 200, G: 100, B: 50, A: 255}
+	c2 := color.NRGBA{R: 100, G: 150, B: 50, A: 255}
+	all := newAccumulators()
+	halves1 := newAccumulators()
+	halves2 := newAccumulators()
+	for i, acc := range all {
+		// Accumulate three colors directly and by merging.
+		acc.Add(c1)
+		acc.Add(c2)
+		acc.Add(c2)
+		h1, h2 := halves1[i], halves2[i]
+		h1.Add(c1)
+		h2.Add(c2)
+		h2.Add(c2)
+		if err := h1.Merge(h2); err != nil {
+			t.Fatalf("%T: %v", h1, err)
+		}
+		if !acc.Valid() || !h1.Valid() {
+			t.Fatalf("expected %v and %v to be valid", acc, h1)
+		}
+		if acc.Weight() != 3.0 || h1.Weight() != 3.0 {
+			t.Fatalf("expected %T to have a weight of 3", acc)
+		}
+		r1, g1, b1, a1 := acc.AverageColor().RGBA()
+		r2, g2, b2, a2 := h1.AverageColor().RGBA()
+		if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+			t.Fatalf("%T: merging produced %v instead of %v",
+				acc, h1.AverageColor(), acc.AverageColor())
+		}
+
+		// Scaling by 2 should double the weight but not change the
+		// average color.
+		if err := acc.ScaleWeight(2.0); err != nil {
+			t.Fatalf("%T: %v", acc, err)
+		}
+		if acc.Weight() != 6.0 {
+			t.Fatalf("expected %T to have a weight of 6", acc)
+		}
+		r3, g3, b3, a3 := acc.AverageColor().RGBA()
+		if r1 != r3 || g1 != g3 || b1 != b3 || a1 != a3 {
+			t.Fatalf("%T: scaling changed %v to %v",
+				acc, h1.AverageColor(), acc.AverageColor())
+		}
+	}
+}
+
+// integerTally returns true if an Accumulator maintains an integer tally.
+func integerTally(acc Accumulator) bool {
+	_, ok := acc.(interface{ Scale(w uint64) })
+	return ok
+}
+
+// TestAccumulatorScaleWeight confirms that ScaleWeight rejects weights that
+// an Accumulator cannot represent and leaves the Accumulator unchanged.
+func TestAccumulatorScaleWeight(t *testing.T) {
+	for _, acc := range newAccumulators() {
+		acc.Add(color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+		for _, w := range []float64{-1.0, math.NaN(), math.Inf(1), 2.5} {
+			err := acc.ScaleWeight(w)
+			if w == 2.5 && !integerTally(acc) {
+				if err != nil {
+					t.Fatalf("%T: %v", acc, err)
+				}
+				acc.ScaleWeight(1.0 / 2.5)
+				continue
+			}
+			if !errors.Is(err, ErrWeight) {
+				t.Fatalf("expected scaling %T by %v to return ErrWeight but saw %v", acc, w, err)
+			}
+			if acc.Weight() != 1.0 {
+				t.Fatalf("expected %T to have a weight of 1 but saw %v", acc, acc.Weight())
+			}
+		}
+	}
+}
+
+// TestAccumulatorMergeOther merges an Accumulator of a different type into
+// every accumulating color type and confirms that the other Accumulator's
+// weight is preserved.
+func TestAccumulatorMergeOther(t *testing.T) {
+	other := &NRGBA64{}
+	other.Add(color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+	other.Scale(5)
+	for _, acc := range newAccumulators() {
+		acc.Add(color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+		if err := acc.Merge(other); err != nil {
+			t.Fatalf("%T: %v", acc, err)
+		}
+		if !acc.Valid() {
+			t.Fatalf("expected %v to be valid", acc)
+		}
+		if acc.Weight() != 6.0 {
+			t.Fatalf("expected %T to have a weight of 6 but saw %v", acc, acc.Weight())
+		}
+	}
+}
+
+// TestAccumulatorMergeFractional merges an Accumulator with a fractional
+// weight into every accumulating color type and confirms that only types with
+// fractional tallies accept it.
+func TestAccumulatorMergeFractional(t *testing.T) {
+	other := &WeightedNRGBA{R: 80.0, G: 40.0, B: 20.0, A: 102.0, Tally: 0.4}
+	for _, acc := range newAccumulators() {
+		acc.Add(color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+		err := acc.Merge(other)
+		switch {
+		case integerTally(acc) && !errors.Is(err, ErrWeight):
+			t.Fatalf("expected merging into %T to return ErrWeight but saw %v", acc, err)
+		case integerTally(acc) && acc.Weight() != 1.0:
+			t.Fatalf("expected %T to have a weight of 1 but saw %v", acc, acc.Weight())
+		case !integerTally(acc) && err != nil:
+			t.Fatalf("%T: %v", acc, err)
+		case !integerTally(acc) && math.Abs(acc.Weight()-1.4) > 1e-12:
+			t.Fatalf("expected %T to have a weight of 1.4 but saw %v", acc, acc.Weight())
+		}
+	}
+}
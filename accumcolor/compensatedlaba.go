@@ -0,0 +1,162 @@
+// This file defines the CompensatedLabA type and associated methods.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// A CompensatedLabA is a color.Color that supports accumulation of CIE L*a*b*
+// color values with Neumaier (improved Kahan) compensated summation.  Alongside
+// each floating-point sum it keeps a running compensation term that captures
+// the low-order bits lost to rounding, making a CompensatedLabA suitable for
+// accumulating hundreds of millions of colors per pixel.  The effective value
+// of each channel is its sum plus its compensation.  An invariant maintained
+// by all methods is that either all fields are zero or each effective value
+// of L, A, and B and the value of Alpha divided by Tally produces a value in
+// its target range.
+type CompensatedLabA struct {
+	L     float64 // [0, 1]*Tally
+	A     float64 // [-1, 1]*Tally
+	B     float64 // [-1, 1]*Tally
+	LComp float64 // Compensation for L
+	AComp float64 // Compensation for A
+	BComp float64 // Compensation for B
+	Alpha uint64  // [0, 255]*Tally
+	Tally uint64
+}
+
+// neumaierAdd adds v to a sum with compensation comp and returns the new sum
+// and compensation.
+func neumaierAdd(sum, comp, v float64) (float64, float64) {
+	t := sum + v
+	if math.Abs(sum) >= math.Abs(v) {
+		comp += (sum - t) + v
+	} else {
+		comp += (v - t) + sum
+	}
+	return t, comp
+}
+
+// Valid returns true if and only if a CompensatedLabA is valid.
+func (c CompensatedLabA) Valid() bool {
+	// The only time a Tally is allowed to be zero is if all other fields
+	// are zero.
+	if c.Tally == 0 {
+		var zero CompensatedLabA
+		return c == zero
+	}
+	return c.LabA().Valid()
+}
+
+// RGBA converts a CompensatedLabA to alpha-premultiplied colors.
+func (c CompensatedLabA) RGBA() (r, g, b, a uint32) {
+	return c.LabA().RGBA()
+}
+
+// accumCompensatedLabAModel is used to define a color model for
+// CompensatedLabA.
+func accumCompensatedLabAModel(c color.Color) color.Color {
+	switch c := c.(type) {
+	case CompensatedLabA:
+		return c
+	case LabA:
+		return CompensatedLabA{
+			L:     c.L,
+			A:     c.A,
+			B:     c.B,
+			Alpha: c.Alpha,
+			Tally: c.Tally,
+		}
+	}
+	lab := LabAModel.Convert(c).(LabA)
+	return CompensatedLabA{
+		L:     lab.L,
+		A:     lab.A,
+		B:     lab.B,
+		Alpha: lab.Alpha,
+		Tally: lab.Tally,
+	}
+}
+
+// CompensatedLabAModel converts any color.Color to a CompensatedLabA color.
+var CompensatedLabAModel = color.ModelFunc(accumCompensatedLabAModel)
+
+// Add accumulates color.
+func (c *CompensatedLabA) Add(clr color.Color) {
+	other := CompensatedLabAModel.Convert(clr).(CompensatedLabA)
+	c.L, c.LComp = neumaierAdd(c.L, c.LComp, other.L)
+	c.A, c.AComp = neumaierAdd(c.A, c.AComp, other.A)
+	c.B, c.BComp = neumaierAdd(c.B, c.BComp, other.B)
+	c.LComp += other.LComp
+	c.AComp += other.AComp
+	c.BComp += other.BComp
+	c.Alpha += other.Alpha
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// a CompensatedLabA is an integer, ScaleWeight returns ErrWeight, leaving the
+// color unchanged, unless w is a nonnegative integer.
+func (c *CompensatedLabA) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies all components of a CompensatedLabA by a given value.
+// This does not change the effective color but can be used for performing
+// weighted averages.
+func (c *CompensatedLabA) Scale(w uint64) {
+	w64 := float64(w)
+	c.L *= w64
+	c.A *= w64
+	c.B *= w64
+	c.LComp *= w64
+	c.AComp *= w64
+	c.BComp *= w64
+	c.Alpha *= w
+	c.Tally *= w
+}
+
+// LabA folds the compensation terms of a CompensatedLabA into its sums to
+// produce an ordinary LabA.
+func (c CompensatedLabA) LabA() LabA {
+	return LabA{
+		L:     c.L + c.LComp,
+		A:     c.A + c.AComp,
+		B:     c.B + c.BComp,
+		Alpha: c.Alpha,
+		Tally: c.Tally,
+	}
+}
+
+// Average averages the accumulated color of a CompensatedLabA to produce a
+// LabA with a Tally of 1.
+func (c CompensatedLabA) Average() LabA {
+	return c.LabA().Average()
+}
+
+// Colorful averages the accumulated color of a CompensatedLabA to produce a
+// colorful.Color (from the go-colorful package).
+func (c CompensatedLabA) Colorful() colorful.Color {
+	return c.LabA().Colorful()
+}
+
+// Weight returns the tally of a CompensatedLabA as a float64.
+func (c CompensatedLabA) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of a CompensatedLabA as a LabA with a
+// Tally of 1.
+func (c CompensatedLabA) AverageColor() color.Color {
+	return c.Average()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into a
+// CompensatedLabA.
+func (c *CompensatedLabA) Merge(other Accumulator) error {
+	return merge(c, CompensatedLabAModel, other)
+}
@@ -0,0 +1,81 @@
+// This file defines a suite of tests for accumcolor.CompensatedLabA.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// TestCompensatedLabAAdd confirms that compensated summation accumulates
+// many colors more accurately than naive summation.
+func TestCompensatedLabAAdd(t *testing.T) {
+	clr := color.NRGBA{R: 30, G: 144, B: 255, A: 255}
+	one := LabAModel.Convert(clr).(LabA)
+	cOne := CompensatedLabAModel.Convert(clr).(CompensatedLabA)
+	var naive LabA
+	var comp CompensatedLabA
+	const n = 1000000
+	for i := 0; i < n; i++ {
+		naive.Add(one)
+		comp.Add(cOne)
+	}
+	if !comp.Valid() {
+		t.Fatalf("expected %v to be valid", comp)
+	}
+	if comp.Tally != n || comp.Alpha != 255*n {
+		t.Fatalf("incorrect tally or alpha in %v", comp)
+	}
+	sum := comp.LabA()
+	for _, ch := range []struct {
+		name        string
+		naive, comp float64
+		exp         float64
+	}{
+		{"L", naive.L, sum.L, one.L * n},
+		{"A", naive.A, sum.A, one.A * n},
+		{"B", naive.B, sum.B, one.B * n},
+	} {
+		naiveErr := math.Abs(ch.naive - ch.exp)
+		compErr := math.Abs(ch.comp - ch.exp)
+		if compErr > 1e-9 {
+			t.Fatalf("expected %s = %.15g but saw %.15g", ch.name, ch.exp, ch.comp)
+		}
+		if compErr > naiveErr {
+			t.Fatalf("compensated error in %s (%g) exceeds naive error (%g)",
+				ch.name, compErr, naiveErr)
+		}
+	}
+}
+
+// TestCompensatedLabAConvert confirms that a CompensatedLabA averages and
+// converts colors as a LabA does.
+func TestCompensatedLabAConvert(t *testing.T) {
+	clrs := []color.Color{
+		color.NRGBA{R: 255, G: 128, B: 0, A: 255},
+		color.NRGBA{R: 0, G: 100, B: 200, A: 128},
+		color.White,
+	}
+	var lab LabA
+	var comp CompensatedLabA
+	for _, c := range clrs {
+		lab.Add(c)
+		comp.Add(c)
+	}
+	comp.Scale(3)
+	lab.Scale(3)
+	avg1, avg2 := lab.Average(), comp.Average()
+	compareFloats(t, "L", avg2.L, avg1.L)
+	compareFloats(t, "A", avg2.A, avg1.A)
+	compareFloats(t, "B", avg2.B, avg1.B)
+	if avg1.Alpha != avg2.Alpha || avg1.Tally != avg2.Tally {
+		t.Fatalf("expected %v but saw %v", avg1, avg2)
+	}
+	r1, g1, b1, a1 := lab.RGBA()
+	r2, g2, b2, a2 := comp.RGBA()
+	if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+		t.Fatalf("expected %v but saw %v", []uint32{r1, g1, b1, a1},
+			[]uint32{r2, g2, b2, a2})
+	}
+}
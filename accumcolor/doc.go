@@ -6,11 +6,40 @@ An AccumNRGBA is analogous to a color.NRGBA but additionally supports adding
 colors together.  It maintains a tally of the total number of colors that have
 been accumulated.  The Add method accumulates more color onto an existing
 AccumNRGBA.  The NRGBA method returns the average color of the entire
-accumulation as a color.NRGBA.
+accumulation as a color.NRGBA.  The Sub method removes a previously accumulated
+color, returning an error rather than letting the tally underflow.  Similarly,
+AddChecked and ScaleChecked return an error rather than letting any sum
+overflow.
 
 An AccumLabA provides similar functionality to AccumNRGBA but stores colors
 in CIE L*a*b* + alpha channels.  AccumLabA thereby supports a more
 perceptually uniform color space and more natural results when averaging
-colors.
+colors.  An OkLabA does the same in the Oklab color space, which blends
+saturated colors, especially blues, even more evenly than CIE L*a*b*.
+
+Other types vary the way colors are represented.  An NRGBA64 is the
+16-bit-per-channel analogue of an AccumNRGBA.  Gray and Gray16 similarly
+accumulate single-channel 8-bit and 16-bit grayscale values.  A LinearNRGBA
+decodes sRGB colors to linear light before accumulating them, which makes
+averaging physically correct.  An RGBA64 accumulates alpha-premultiplied colors
+so that transparent colors do not tint the average.  LChA, HSVA, and HSLA
+accumulate colors in cylindrical color spaces.  Each accumulates hue as a sum
+of vectors weighted by chroma or saturation so that averaging hues produces a
+circular mean rather than a linear one.
+
+Still other types vary the way colors are accumulated.  WeightedNRGBA and
+WeightedLabA store their sums and tally as floating-point numbers so that
+colors can be accumulated with fractional weights using AddWeighted.
+NRGBAStats and LabAStats use Welford's online algorithm to track each channel's
+variance in addition to its mean.  MinNRGBA and MaxNRGBA accumulate the
+per-channel minimum and maximum, respectively, of all colors added to them
+rather than their mean.  A CompensatedLabA is a LabA that uses Neumaier
+compensated summation to limit the rounding error that builds up over very long
+accumulations.
+
+Pointers to all of the above types implement the Accumulator interface, which
+provides a common set of methods for accumulating, weighting, merging, and
+averaging colors.  Code written in terms of Accumulators therefore works with
+any accumulating color type.
 */
 package accumcolor
@@ -0,0 +1,22 @@
+// This file defines the errors that accumcolor methods can return.
+
+package accumcolor
+
+import "errors"
+
+// ErrUnderflow is returned when removing a color would drive a sum or a tally
+// below zero, typically because the color was never accumulated.
+var ErrUnderflow = errors.New("accumcolor: subtraction underflows")
+
+// ErrOverflow is returned when accumulating or scaling a color would overflow
+// one of its sums or its tally.
+var ErrOverflow = errors.New("accumcolor: accumulation overflows")
+
+// ErrInvalid is returned when an operation would violate a color's Valid
+// invariant.
+var ErrInvalid = errors.New("accumcolor: operation produces an invalid color")
+
+// ErrWeight is returned when a color cannot be scaled by a weight, either
+// because the weight is negative or not finite or because the color maintains
+// an integer tally and the weight is not an integer.
+var ErrWeight = errors.New("accumcolor: weight cannot be represented")
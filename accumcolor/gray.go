@@ -0,0 +1,179 @@
+// This file defines the Gray and Gray16 types and associated methods.
+
+package accumcolor
+
+import "image/color"
+
+// A Gray is a color.Color that supports accumulation of 8-bit grayscale
+// values.  An invariant maintained by all methods is that either both fields
+// are zero or Y divided by Tally produces a value in the range [0, 255].
+type Gray struct {
+	Y     uint64
+	Tally uint64
+}
+
+// Valid returns true if and only if a Gray is valid.
+func (c Gray) Valid() bool {
+	if c.Tally == 0 {
+		// The only time a Tally is allowed to be zero is if Y is also
+		// zero.
+		return c.Y == 0
+	}
+	return c.Y/c.Tally <= 255
+}
+
+// RGBA converts a Gray to alpha-premultiplied colors.
+func (c Gray) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0 {
+		return
+	}
+	return c.Gray().RGBA()
+}
+
+// accumGrayModel is used to define a color model for Gray.
+func accumGrayModel(c color.Color) color.Color {
+	if _, ok := c.(Gray); ok {
+		return c
+	}
+	gray := color.GrayModel.Convert(c).(color.Gray)
+	return Gray{
+		Y:     uint64(gray.Y),
+		Tally: 1,
+	}
+}
+
+// GrayModel converts any color.Color to a Gray color.
+var GrayModel = color.ModelFunc(accumGrayModel)
+
+// Add accumulates color.
+func (c *Gray) Add(clr color.Color) {
+	other := GrayModel.Convert(clr).(Gray)
+	c.Y += other.Y
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// a Gray is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *Gray) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies both components of a Gray by a given value.  This does not
+// change the effective color but can be used for performing weighted averages.
+func (c *Gray) Scale(w uint64) {
+	c.Y *= w
+	c.Tally *= w
+}
+
+// Gray averages the accumulated color of a Gray to produce an ordinary
+// color.Gray.
+func (c Gray) Gray() color.Gray {
+	if c.Tally == 0 {
+		return color.Gray{}
+	}
+	return color.Gray{Y: uint8(c.Y / c.Tally)}
+}
+
+// Weight returns the tally of a Gray as a float64.
+func (c Gray) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of a Gray as a color.Gray.
+func (c Gray) AverageColor() color.Color {
+	return c.Gray()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into a Gray.
+func (c *Gray) Merge(other Accumulator) error {
+	return merge(c, GrayModel, other)
+}
+
+// A Gray16 is a color.Color that supports accumulation of 16-bit grayscale
+// values.  An invariant maintained by all methods is that either both fields
+// are zero or Y divided by Tally produces a value in the range [0, 65535].
+type Gray16 struct {
+	Y     uint64
+	Tally uint64
+}
+
+// Valid returns true if and only if a Gray16 is valid.
+func (c Gray16) Valid() bool {
+	if c.Tally == 0 {
+		// The only time a Tally is allowed to be zero is if Y is also
+		// zero.
+		return c.Y == 0
+	}
+	return c.Y/c.Tally <= 65535
+}
+
+// RGBA converts a Gray16 to alpha-premultiplied colors.
+func (c Gray16) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0 {
+		return
+	}
+	return c.Gray16().RGBA()
+}
+
+// accumGray16Model is used to define a color model for Gray16.
+func accumGray16Model(c color.Color) color.Color {
+	if _, ok := c.(Gray16); ok {
+		return c
+	}
+	gray := color.Gray16Model.Convert(c).(color.Gray16)
+	return Gray16{
+		Y:     uint64(gray.Y),
+		Tally: 1,
+	}
+}
+
+// Gray16Model converts any color.Color to a Gray16 color.
+var Gray16Model = color.ModelFunc(accumGray16Model)
+
+// Add accumulates color.
+func (c *Gray16) Add(clr color.Color) {
+	other := Gray16Model.Convert(clr).(Gray16)
+	c.Y += other.Y
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// a Gray16 is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *Gray16) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies both components of a Gray16 by a given value.  This does
+// not change the effective color but can be used for performing weighted
+// averages.
+func (c *Gray16) Scale(w uint64) {
+	c.Y *= w
+	c.Tally *= w
+}
+
+// Gray16 averages the accumulated color of a Gray16 to produce an ordinary
+// color.Gray16.
+func (c Gray16) Gray16() color.Gray16 {
+	if c.Tally == 0 {
+		return color.Gray16{}
+	}
+	return color.Gray16{Y: uint16(c.Y / c.Tally)}
+}
+
+// Weight returns the tally of a Gray16 as a float64.
+func (c Gray16) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of a Gray16 as a color.Gray16.
+func (c Gray16) AverageColor() color.Color {
+	return c.Gray16()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into a
+// Gray16.
+func (c *Gray16) Merge(other Accumulator) error {
+	return merge(c, Gray16Model, other)
+}
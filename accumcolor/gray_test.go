@@ -0,0 +1,62 @@
+// This file defines a suite of tests for accumcolor.Gray and
+// accumcolor.Gray16.
+
+package accumcolor
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestGrayValid ensures we can distinguish valid from invalid colors.
+func TestGrayValid(t *testing.T) {
+	var c Gray
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.Y = 123
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 1
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.Y = 256
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 2
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c16 := Gray16{Y: 65536, Tally: 1}
+	if c16.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c16)
+	}
+	c16.Tally = 2
+	if !c16.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c16)
+	}
+}
+
+// TestGrayAverage ensures that averaging colors produces the expected result.
+func TestGrayAverage(t *testing.T) {
+	var sum Gray
+	sum.Add(color.Gray{Y: 100})
+	sum.Add(color.Gray{Y: 201})
+	sum.Add(color.White)
+	if act, exp := sum.Gray(), (color.Gray{Y: 185}); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+	var sum16 Gray16
+	sum16.Add(color.Gray16{Y: 0x1234})
+	sum16.Add(Gray16{Y: 0x5678, Tally: 1})
+	sum16.Scale(3)
+	if sum16.Tally != 6 {
+		t.Fatalf("expected Tally = 6 but saw %d", sum16.Tally)
+	}
+	if act, exp := sum16.Gray16(), (color.Gray16{Y: 0x3456}); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+}
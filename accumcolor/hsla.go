@@ -0,0 +1,173 @@
+// This file defines the HSLA type and associated methods.
+
+package accumcolor
+
+import (
+	"image/color"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// An HSLA is a color.Color that supports accumulation of HSL (hue, saturation,
+// lightness) color values.  Hue is accumulated as a sum of vectors whose
+// direction is the hue angle and whose length is the saturation so that
+// averaging produces a circular mean; red at 350° and red at 10° average to
+// red at 0°, not cyan at 180°.  An invariant maintained by all methods is that
+// either all fields are zero or each of S, L, and Alpha divided by Tally
+// produces a value in its target range and the hue vector is no longer than
+// S.
+type HSLA struct {
+	HueX  float64 // Sum of saturation*cos(hue)
+	HueY  float64 // Sum of saturation*sin(hue)
+	S     float64 // [0, 1]*Tally
+	L     float64 // [0, 1]*Tally
+	Alpha uint64  // [0, 255]*Tally
+	Tally uint64
+}
+
+// Valid returns true if and only if an HSLA is valid.
+func (c HSLA) Valid() bool {
+	// The only time a Tally is allowed to be zero is if all other fields
+	// are zero.
+	if c.Tally == 0 {
+		var zero HSLA
+		return c == zero
+	}
+
+	// If Tally is nonzero, each other field divided by it must lie within
+	// its target range.
+	tally := float64(c.Tally)
+	s := c.S / tally
+	l := c.L / tally
+	alpha := c.Alpha / c.Tally
+	switch {
+	case s < 0.0 || s > 1.0:
+		return false
+	case l < 0.0 || l > 1.0:
+		return false
+	case !validHueVector(c.HueX, c.HueY, c.S):
+		return false
+	case alpha > 255:
+		return false
+	default:
+		return true
+	}
+}
+
+// RGBA converts an HSLA to alpha-premultiplied colors.
+func (c HSLA) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0 {
+		return
+	}
+	tally := float64(c.Tally)
+	clr := c.Colorful().Clamped()
+	alpha := float64(c.Alpha) / tally / 255.0
+	r = uint32(clr.R*alpha*65535.0 + 0.5)
+	g = uint32(clr.G*alpha*65535.0 + 0.5)
+	b = uint32(clr.B*alpha*65535.0 + 0.5)
+	a = uint32(alpha*65535.0 + 0.5)
+	return
+}
+
+// accumHSLAModel is used to define a color model for HSLA.
+func accumHSLAModel(c color.Color) color.Color {
+	if _, ok := c.(HSLA); ok {
+		return c
+	}
+	clr, _ := colorful.MakeColor(c)
+	h, s, l := clr.Hsl()
+	hx, hy := hueVector(h, s)
+	_, _, _, alpha := c.RGBA()
+	return HSLA{
+		HueX:  hx,
+		HueY:  hy,
+		S:     s,
+		L:     l,
+		Alpha: uint64(alpha >> 8),
+		Tally: 1,
+	}
+}
+
+// HSLAModel converts any color.Color to an HSLA color.
+var HSLAModel = color.ModelFunc(accumHSLAModel)
+
+// Add accumulates color.
+func (c *HSLA) Add(clr color.Color) {
+	other := HSLAModel.Convert(clr).(HSLA)
+	c.HueX += other.HueX
+	c.HueY += other.HueY
+	c.S += other.S
+	c.L += other.L
+	c.Alpha += other.Alpha
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// an HSLA is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *HSLA) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies all components of an HSLA by a given value.  This does not
+// change the effective color but can be used for performing weighted averages.
+func (c *HSLA) Scale(w uint64) {
+	w64 := float64(w)
+	c.HueX *= w64
+	c.HueY *= w64
+	c.S *= w64
+	c.L *= w64
+	c.Alpha *= w
+	c.Tally *= w
+}
+
+// Hue returns the circular mean of the accumulated hues in degrees, in the
+// range [0, 360).  Hue returns 0 if the hues cancel out or if all accumulated
+// colors are unsaturated.
+func (c HSLA) Hue() float64 {
+	return vectorHue(c.HueX, c.HueY)
+}
+
+// Average averages the accumulated color of an HSLA to produce an HSLA with a
+// Tally of 1.  The hue vector of the result has the circular-mean hue and a
+// length equal to the mean saturation.
+func (c HSLA) Average() HSLA {
+	if c.Tally == 0 {
+		return HSLA{}
+	}
+	tally := float64(c.Tally)
+	s := c.S / tally
+	hx, hy := hueVector(c.Hue(), s)
+	return HSLA{
+		HueX:  hx,
+		HueY:  hy,
+		S:     s,
+		L:     c.L / tally,
+		Alpha: c.Alpha / c.Tally,
+		Tally: 1,
+	}
+}
+
+// Colorful averages the accumulated color of an HSLA to produce a
+// colorful.Color (from the go-colorful package).
+func (c HSLA) Colorful() colorful.Color {
+	avg := c.Average()
+	return colorful.Hsl(c.Hue(), avg.S, avg.L)
+}
+
+// Weight returns the tally of an HSLA as a float64.
+func (c HSLA) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of an HSLA as an HSLA with a
+// Tally of 1.
+func (c HSLA) AverageColor() color.Color {
+	return c.Average()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into an
+// HSLA.
+func (c *HSLA) Merge(other Accumulator) error {
+	return merge(c, HSLAModel, other)
+}
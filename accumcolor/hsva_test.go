@@ -0,0 +1,29 @@
+// This file defines a suite of tests for accumcolor.HSVA.
+
+package accumcolor
+
+import "testing"
+
+// TestHSVAValid ensures we can distinguish valid from invalid colors.
+func TestHSVAValid(t *testing.T) {
+	var c HSVA
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.V = 0.5
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 1
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.HueY = -0.25
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.S = 0.25
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+}
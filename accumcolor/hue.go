@@ -0,0 +1,31 @@
+// This file defines helper functions for accumulating hue angles.
+
+package accumcolor
+
+import "math"
+
+// hueVector converts a hue angle in degrees to a vector whose length is a
+// given weight (typically chroma or saturation).  Summing such vectors and
+// taking the angle of the result produces a weighted circular mean.
+func hueVector(h, w float64) (x, y float64) {
+	sin, cos := math.Sincos(h * math.Pi / 180.0)
+	return w * cos, w * sin
+}
+
+// vectorHue converts a (sum of) hue vector(s) back to a hue angle in degrees
+// in the range [0, 360).  If the vector has length zero, the hue is undefined,
+// and vectorHue returns 0.
+func vectorHue(x, y float64) float64 {
+	h := math.Atan2(y, x) * 180.0 / math.Pi
+	if h < 0.0 {
+		h += 360.0
+	}
+	return h
+}
+
+// validHueVector returns true if and only if a (sum of) hue vector(s) is no
+// longer than the sum of the weights used to produce it, allowing for a small
+// amount of round-off error.
+func validHueVector(x, y, w float64) bool {
+	return math.Hypot(x, y) <= w*(1.0+1e-9)+1e-9
+}
@@ -0,0 +1,151 @@
+// This file defines a suite of tests for the accumulating color types that
+// average hues circularly: HSLA, HSVA, and LChA.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// compareHues aborts if two hue angles are not within some threshold distance
+// of each other on the color wheel.  The threshold accommodates the 16-bit
+// quantization that occurs when converting colors to an accumulating type.
+func compareHues(t *testing.T, act, exp float64) {
+	const maxDiffAllowed = 1e-2
+	diff := math.Abs(math.Mod(act-exp+540.0, 360.0) - 180.0)
+	if diff > maxDiffAllowed {
+		t.Fatalf("expected hue = %v but saw %v", exp, act)
+	}
+}
+
+// compareQuantized aborts if two values in [0, 1] differ by more than the
+// 16-bit quantization that occurs when converting colors to an accumulating
+// type can explain.
+func compareQuantized(t *testing.T, nm string, act, exp float64) {
+	const maxDiffAllowed = 1e-4
+	if math.Abs(exp-act) > maxDiffAllowed {
+		t.Fatalf("expected %s = %v but saw %v", nm, exp, act)
+	}
+}
+
+// A hueAccumulator is an Accumulator that averages hues circularly.
+type hueAccumulator interface {
+	Accumulator
+	Hue() float64
+}
+
+// TestHueTypes exercises each accumulating color type that averages hues
+// circularly.
+func TestHueTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		model color.Model
+		zero  func() hueAccumulator
+		hue   func(h float64) colorful.Color // A saturated color of hue h
+		check func(t *testing.T)             // A model-specific check
+	}{
+		{
+			name:  "HSLA",
+			model: HSLAModel,
+			zero:  func() hueAccumulator { return &HSLA{} },
+			hue:   func(h float64) colorful.Color { return colorful.Hsl(h, 1.0, 0.5) },
+			check: func(t *testing.T) {
+				// Lightness should average linearly even as
+				// hue averages circularly.
+				var sum HSLA
+				sum.Add(colorful.Hsl(350.0, 1.0, 0.2))
+				sum.Add(colorful.Hsl(10.0, 1.0, 0.6))
+				avg := sum.Average()
+				compareHues(t, avg.Hue(), 0.0)
+				compareQuantized(t, "L", avg.L, 0.4)
+			},
+		},
+		{
+			name:  "HSVA",
+			model: HSVAModel,
+			zero:  func() hueAccumulator { return &HSVA{} },
+			hue:   func(h float64) colorful.Color { return colorful.Hsv(h, 1.0, 1.0) },
+			check: func(t *testing.T) {
+				// Value should average linearly even as hue
+				// averages circularly.
+				var sum HSVA
+				sum.Add(colorful.Hsv(350.0, 1.0, 0.4))
+				sum.Add(colorful.Hsv(10.0, 1.0, 0.8))
+				avg := sum.Average()
+				compareHues(t, avg.Hue(), 0.0)
+				compareQuantized(t, "V", avg.V, 0.6)
+			},
+		},
+		{
+			name:  "LChA",
+			model: LChAModel,
+			zero:  func() hueAccumulator { return &LChA{} },
+			hue:   func(h float64) colorful.Color { return colorful.Hcl(h, 0.4, 0.5) },
+			check: func(t *testing.T) {
+				// Lightness and chroma should average
+				// linearly even as hue averages circularly.
+				var sum LChA
+				sum.Add(colorful.Hcl(350.0, 0.3, 0.4))
+				sum.Add(colorful.Hcl(10.0, 0.3, 0.6))
+				avg := sum.Average()
+				compareHues(t, avg.Hue(), 0.0)
+				compareQuantized(t, "L", avg.L, 0.5)
+				compareQuantized(t, "C", avg.C, 0.3)
+			},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			// Hues on either side of 0° should average to 0°,
+			// not 180°.
+			sum := tst.zero()
+			sum.Add(tst.hue(350.0))
+			sum.Add(tst.hue(10.0))
+			if !sum.Valid() {
+				t.Fatalf("expected %v to be valid, but it is deemed invalid", sum)
+			}
+			compareHues(t, sum.Hue(), 0.0)
+			exp := color.NRGBAModel.Convert(tst.hue(0.0)).(color.NRGBA)
+			act := color.NRGBAModel.Convert(sum).(color.NRGBA)
+			if absDiff(act.R, exp.R) > 1 || absDiff(act.G, exp.G) > 1 ||
+				absDiff(act.B, exp.B) > 1 || act.A != exp.A {
+				t.Fatalf("expected %v but saw %v", exp, act)
+			}
+
+			// Weighting one color more heavily should pull the
+			// hue toward it.
+			sum = tst.zero()
+			heavy := tst.zero()
+			heavy.Add(tst.hue(300.0))
+			if err := heavy.ScaleWeight(3.0); err != nil {
+				t.Fatal(err)
+			}
+			if err := sum.Merge(heavy); err != nil {
+				t.Fatal(err)
+			}
+			sum.Add(tst.hue(30.0))
+			compareHues(t, sum.Hue(), 300.0+math.Atan(1.0/3.0)*180.0/math.Pi)
+
+			// Converting to and from the type should be lossless.
+			rgba := color.RGBA{R: 0x22, G: 0x44, B: 0x66, A: 0x88}
+			rgba2 := color.RGBAModel.Convert(tst.model.Convert(rgba)).(color.RGBA)
+			if rgba != rgba2 {
+				t.Fatalf("expected RGBA = %v but saw %v", rgba, rgba2)
+			}
+
+			tst.check(t)
+		})
+	}
+}
+
+// absDiff returns the absolute difference of two uint8s.
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -96,6 +96,76 @@ func (c *LabA) Add(clr color.Color) {
 	c.Tally += other.Tally
 }
 
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// a LabA is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *LabA) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// AddChecked is like Add but returns ErrOverflow and leaves the LabA
+// unmodified if accumulating the color would overflow Alpha or Tally.
+func (c *LabA) AddChecked(clr color.Color) error {
+	other := LabAModel.Convert(clr).(LabA)
+	alpha, ovA := checkedAdd(c.Alpha, other.Alpha)
+	tally, ovT := checkedAdd(c.Tally, other.Tally)
+	if ovA || ovT {
+		return ErrOverflow
+	}
+	c.L += other.L
+	c.A += other.A
+	c.B += other.B
+	c.Alpha = alpha
+	c.Tally = tally
+	return nil
+}
+
+// snapToRange returns v clamped to [lo, hi] if it lies no more than eps
+// outside that range.  The second return value is false if v lies farther
+// outside the range.
+func snapToRange(v, lo, hi, eps float64) (float64, bool) {
+	switch {
+	case v < lo-eps || v > hi+eps:
+		return v, false
+	case v < lo:
+		return lo, true
+	case v > hi:
+		return hi, true
+	default:
+		return v, true
+	}
+}
+
+// Sub removes color previously accumulated with Add.  It returns ErrUnderflow
+// if the alpha channel or tally of the color to remove exceeds that of the
+// LabA and ErrInvalid if the difference is not a valid LabA.  In either case,
+// the LabA is left unmodified.  Floating-point rounding error left over from
+// the subtraction is clamped away.
+func (c *LabA) Sub(clr color.Color) error {
+	other := LabAModel.Convert(clr).(LabA)
+	if other.Alpha > c.Alpha || other.Tally > c.Tally {
+		return ErrUnderflow
+	}
+	diff := LabA{
+		Alpha: c.Alpha - other.Alpha,
+		Tally: c.Tally - other.Tally,
+	}
+	if diff.Tally == 0 && diff.Alpha != 0 {
+		return ErrInvalid
+	}
+	tally := float64(diff.Tally)
+	eps := 1e-9 * float64(c.Tally)
+	var okL, okA, okB bool
+	diff.L, okL = snapToRange(c.L-other.L, 0.0, tally, eps)
+	diff.A, okA = snapToRange(c.A-other.A, -tally, tally, eps)
+	diff.B, okB = snapToRange(c.B-other.B, -tally, tally, eps)
+	if !okL || !okA || !okB || !diff.Valid() {
+		return ErrInvalid
+	}
+	*c = diff
+	return nil
+}
+
 // Scale multiplies all components of a LabA by a given value.  This
 // does not change the effective color but can be used for performing weighted
 // averages.
@@ -108,6 +178,23 @@ func (c *LabA) Scale(w uint64) {
 	c.Tally *= w
 }
 
+// ScaleChecked is like Scale but returns ErrOverflow and leaves the LabA
+// unmodified if scaling would overflow Alpha or Tally.
+func (c *LabA) ScaleChecked(w uint64) error {
+	alpha, ovA := checkedMul(c.Alpha, w)
+	tally, ovT := checkedMul(c.Tally, w)
+	if ovA || ovT {
+		return ErrOverflow
+	}
+	w64 := float64(w)
+	c.L *= w64
+	c.A *= w64
+	c.B *= w64
+	c.Alpha = alpha
+	c.Tally = tally
+	return nil
+}
+
 // Average averages the accumulated color of a LabA to produce an
 // LabA with a Tally of 1.
 func (c LabA) Average() LabA {
@@ -130,3 +217,19 @@ func (c LabA) Colorful() colorful.Color {
 	avg := c.Average()
 	return colorful.Lab(avg.L, avg.A, avg.B)
 }
+
+// Weight returns the tally of a LabA as a float64.
+func (c LabA) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of a LabA as a LabA with a
+// Tally of 1.
+func (c LabA) AverageColor() color.Color {
+	return c.Average()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into a LabA.
+func (c *LabA) Merge(other Accumulator) error {
+	return merge(c, LabAModel, other)
+}
@@ -3,6 +3,7 @@
 package accumcolor
 
 import (
+	"errors"
 	"image/color"
 	"math"
 	"testing"
@@ -163,3 +164,67 @@ func TestLabAScale(t *testing.T) {
 		t.Fatalf("expected %v but saw %v", orange, nrgba)
 	}
 }
+
+// TestLabASub confirms that Sub undoes Add and refuses to underflow.
+func TestLabASub(t *testing.T) {
+	c1 := colorful.Color{R: 0.1, G: 0.5, B: 0.9}
+	c2 := colorful.Color{R: 1.0, G: 0.8, B: 0.0}
+	var c LabA
+	c.Add(c1)
+	c.Add(c2)
+	if err := c.Sub(c2); err != nil {
+		t.Fatal(err)
+	}
+	exp := LabAModel.Convert(c1).(LabA)
+	compareFloats(t, "L", c.L, exp.L)
+	compareFloats(t, "A", c.A, exp.A)
+	compareFloats(t, "B", c.B, exp.B)
+	if c.Alpha != exp.Alpha || c.Tally != exp.Tally {
+		t.Fatalf("expected %v but saw %v", exp, c)
+	}
+
+	// Removing the final color should zero out c.
+	if err := c.Sub(c1); err != nil {
+		t.Fatal(err)
+	}
+	if c != (LabA{}) {
+		t.Fatalf("expected %v to be zero", c)
+	}
+	if err := c.Sub(c1); !errors.Is(err, ErrUnderflow) {
+		t.Fatalf("expected ErrUnderflow but saw %v", err)
+	}
+
+	// Removing a color that was never added should fail without
+	// modifying c.
+	c.Add(color.Black)
+	c.Add(color.Black)
+	before := c
+	if err := c.Sub(color.White); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid but saw %v", err)
+	}
+	if c != before {
+		t.Fatalf("expected %v but saw %v", before, c)
+	}
+}
+
+// TestLabAChecked confirms that AddChecked and ScaleChecked detect overflow.
+func TestLabAChecked(t *testing.T) {
+	var c LabA
+	if err := c.AddChecked(color.White); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ScaleChecked(1 << 40); err != nil {
+		t.Fatal(err)
+	}
+	compareFloats(t, "L", c.L/float64(c.Tally), 1.0)
+	before := c
+	if err := c.ScaleChecked(1 << 20); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow but saw %v", err)
+	}
+	if err := c.AddChecked(LabA{Tally: ^uint64(0)}); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow but saw %v", err)
+	}
+	if c != before {
+		t.Fatalf("expected %v but saw %v", before, c)
+	}
+}
@@ -0,0 +1,194 @@
+// This file defines the LabAStats type and associated methods.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// A LabAStats is a color.Color that supports accumulation of CIE L*a*b* color
+// values while tracking not only each channel's mean but also its variance.
+// This makes it possible to determine how well an average has converged.  An
+// invariant maintained by all methods is that either all fields are zero or
+// Tally is nonzero, each channel's mean lies within its target range, and each
+// channel's M2 is nonnegative.
+type LabAStats struct {
+	L     Moments // Mean in [0, 1]
+	A     Moments // Mean in [-1, 1]
+	B     Moments // Mean in [-1, 1]
+	Alpha Moments // Mean in [0, 255]
+	Tally uint64
+}
+
+// Valid returns true if and only if a LabAStats is valid.
+func (c LabAStats) Valid() bool {
+	switch {
+	case c.Tally == 0:
+		// The only time a Tally is allowed to be zero is if all other
+		// fields are zero.
+		var zero LabAStats
+		return c == zero
+	case !c.L.valid(0.0, 1.0):
+		return false
+	case !c.A.valid(-1.0, 1.0):
+		return false
+	case !c.B.valid(-1.0, 1.0):
+		return false
+	case !c.Alpha.valid(0.0, 255.0):
+		return false
+	default:
+		return true
+	}
+}
+
+// RGBA converts the mean of a LabAStats to alpha-premultiplied colors.
+func (c LabAStats) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0 {
+		return
+	}
+	clr := c.Colorful().Clamped()
+	alpha := c.Alpha.Mean / 255.0
+	r = uint32(clr.R*alpha*65535.0 + 0.5)
+	g = uint32(clr.G*alpha*65535.0 + 0.5)
+	b = uint32(clr.B*alpha*65535.0 + 0.5)
+	a = uint32(alpha*65535.0 + 0.5)
+	return
+}
+
+// accumLabAStatsModel is used to define a color model for LabAStats.
+func accumLabAStatsModel(c color.Color) color.Color {
+	switch c := c.(type) {
+	case LabAStats:
+		return c
+	case LabA:
+		// A LabA records no information about variance.  We
+		// therefore treat it as Tally copies of its average color.
+		if c.Tally == 0 {
+			return LabAStats{}
+		}
+		tally := float64(c.Tally)
+		return LabAStats{
+			L:     Moments{Mean: c.L / tally},
+			A:     Moments{Mean: c.A / tally},
+			B:     Moments{Mean: c.B / tally},
+			Alpha: Moments{Mean: float64(c.Alpha) / tally},
+			Tally: c.Tally,
+		}
+	}
+	clr, _ := colorful.MakeColor(c)
+	L, a, b := clr.Lab()
+	_, _, _, alpha := c.RGBA()
+	return LabAStats{
+		L:     Moments{Mean: L},
+		A:     Moments{Mean: a},
+		B:     Moments{Mean: b},
+		Alpha: Moments{Mean: float64(alpha >> 8)},
+		Tally: 1,
+	}
+}
+
+// LabAStatsModel converts any color.Color to a LabAStats color.
+var LabAStatsModel = color.ModelFunc(accumLabAStatsModel)
+
+// Add accumulates color.
+func (c *LabAStats) Add(clr color.Color) {
+	other := LabAStatsModel.Convert(clr).(LabAStats)
+	c.L.merge(c.Tally, other.L, other.Tally)
+	c.A.merge(c.Tally, other.A, other.Tally)
+	c.B.merge(c.Tally, other.B, other.Tally)
+	c.Alpha.merge(c.Tally, other.Alpha, other.Tally)
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// a LabAStats is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *LabAStats) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies the tally of a LabAStats by a given value as if each color
+// accumulated so far had been accumulated w times.  This does not change the
+// mean color but can be used for performing weighted averages.
+func (c *LabAStats) Scale(w uint64) {
+	c.L.scale(w)
+	c.A.scale(w)
+	c.B.scale(w)
+	c.Alpha.scale(w)
+	c.Tally *= w
+}
+
+// Mean returns the mean of each channel of a LabAStats.
+func (c LabAStats) Mean() (L, a, b, alpha float64) {
+	return c.L.Mean, c.A.Mean, c.B.Mean, c.Alpha.Mean
+}
+
+// Variance returns the unbiased sample variance of each channel of a
+// LabAStats.  All variances are zero if fewer than two colors have been
+// accumulated.
+func (c LabAStats) Variance() (L, a, b, alpha float64) {
+	L = c.L.variance(c.Tally)
+	a = c.A.variance(c.Tally)
+	b = c.B.variance(c.Tally)
+	alpha = c.Alpha.variance(c.Tally)
+	return
+}
+
+// StdDev returns the sample standard deviation of each channel of a
+// LabAStats.
+func (c LabAStats) StdDev() (L, a, b, alpha float64) {
+	L, a, b, alpha = c.Variance()
+	return math.Sqrt(L), math.Sqrt(a), math.Sqrt(b), math.Sqrt(alpha)
+}
+
+// StandardError returns the standard error of the mean of each channel of a
+// LabAStats.  This indicates how far the mean is likely to lie from the true
+// mean of the distribution from which colors are drawn.
+func (c LabAStats) StandardError() (L, a, b, alpha float64) {
+	if c.Tally == 0 {
+		return
+	}
+	L, a, b, alpha = c.StdDev()
+	sqrtN := math.Sqrt(float64(c.Tally))
+	return L / sqrtN, a / sqrtN, b / sqrtN, alpha / sqrtN
+}
+
+// Colorful returns the mean of a LabAStats as a colorful.Color (from the
+// go-colorful package).
+func (c LabAStats) Colorful() colorful.Color {
+	return colorful.Lab(c.L.Mean, c.A.Mean, c.B.Mean)
+}
+
+// LabA returns the mean of a LabAStats as a LabA with a Tally of 1.
+func (c LabAStats) LabA() LabA {
+	if c.Tally == 0 {
+		return LabA{}
+	}
+	return LabA{
+		L:     c.L.Mean,
+		A:     c.A.Mean,
+		B:     c.B.Mean,
+		Alpha: uint64(c.Alpha.Mean + 0.5),
+		Tally: 1,
+	}
+}
+
+// Weight returns the tally of a LabAStats as a float64.
+func (c LabAStats) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the mean color of a LabAStats as a LabA with a
+// Tally of 1.
+func (c LabAStats) AverageColor() color.Color {
+	return c.LabA()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into a
+// LabAStats.
+func (c *LabAStats) Merge(other Accumulator) error {
+	return merge(c, LabAStatsModel, other)
+}
@@ -0,0 +1,45 @@
+// This file defines a suite of tests for accumcolor.LabAStats.
+
+package accumcolor
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestLabAStatsVariance ensures that accumulating colors produces the
+// expected mean and variance.
+func TestLabAStatsVariance(t *testing.T) {
+	var c LabAStats
+	for _, v := range []float64{0.2, 0.4, 0.4, 0.4, 0.5, 0.5, 0.7, 0.9} {
+		c.Add(LabA{L: v, A: -v, B: v / 2.0, Alpha: 255, Tally: 1})
+	}
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	L, a, b, alpha := c.Mean()
+	compareFloats(t, "mean L", L, 0.5)
+	compareFloats(t, "mean A", a, -0.5)
+	compareFloats(t, "mean B", b, 0.25)
+	compareFloats(t, "mean Alpha", alpha, 255.0)
+	L, a, b, alpha = c.Variance()
+	compareFloats(t, "variance L", L, 0.32/7.0)
+	compareFloats(t, "variance A", a, 0.32/7.0)
+	compareFloats(t, "variance B", b, 0.08/7.0)
+	compareFloats(t, "variance Alpha", alpha, 0.0)
+}
+
+// TestLabAStatsConvert ensures that we can convert to and from a LabAStats.
+func TestLabAStatsConvert(t *testing.T) {
+	rgba := color.RGBA{
+		R: 0x22,
+		G: 0x44,
+		B: 0x66,
+		A: 0x88,
+	}
+	stats := LabAStatsModel.Convert(rgba).(LabAStats)
+	rgba2 := color.RGBAModel.Convert(stats).(color.RGBA)
+	if rgba != rgba2 {
+		t.Fatalf("expected RGBA = %v but saw %v", rgba, rgba2)
+	}
+}
@@ -0,0 +1,172 @@
+// This file defines the LChA type and associated methods.
+
+package accumcolor
+
+import (
+	"image/color"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// An LChA is a color.Color that supports accumulation of CIE LCh(ab) color
+// values.  Hue is accumulated as a sum of vectors whose direction is the hue
+// angle and whose length is the chroma so that averaging produces a circular
+// mean; red at 350° and red at 10° average to red at 0°, not cyan at 180°.  An
+// invariant maintained by all methods is that either all fields are zero or
+// each of L, C, and Alpha divided by Tally produces a value in its target range
+// and the hue vector is no longer than C.
+type LChA struct {
+	L     float64 // [0, 1]*Tally
+	C     float64 // [0, 1.5]*Tally
+	HueX  float64 // Sum of chroma*cos(hue)
+	HueY  float64 // Sum of chroma*sin(hue)
+	Alpha uint64  // [0, 255]*Tally
+	Tally uint64
+}
+
+// Valid returns true if and only if an LChA is valid.
+func (c LChA) Valid() bool {
+	// The only time a Tally is allowed to be zero is if all other fields
+	// are zero.
+	if c.Tally == 0 {
+		var zero LChA
+		return c == zero
+	}
+
+	// If Tally is nonzero, each other field divided by it must lie within
+	// its target range.
+	tally := float64(c.Tally)
+	L := c.L / tally
+	C := c.C / tally
+	alpha := c.Alpha / c.Tally
+	switch {
+	case L < 0.0 || L > 1.0:
+		return false
+	case C < 0.0 || C > 1.5:
+		return false
+	case !validHueVector(c.HueX, c.HueY, c.C):
+		return false
+	case alpha > 255:
+		return false
+	default:
+		return true
+	}
+}
+
+// RGBA converts an LChA to alpha-premultiplied colors.
+func (c LChA) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0 {
+		return
+	}
+	tally := float64(c.Tally)
+	clr := c.Colorful().Clamped()
+	alpha := float64(c.Alpha) / tally / 255.0
+	r = uint32(clr.R*alpha*65535.0 + 0.5)
+	g = uint32(clr.G*alpha*65535.0 + 0.5)
+	b = uint32(clr.B*alpha*65535.0 + 0.5)
+	a = uint32(alpha*65535.0 + 0.5)
+	return
+}
+
+// accumLChAModel is used to define a color model for LChA.
+func accumLChAModel(c color.Color) color.Color {
+	if _, ok := c.(LChA); ok {
+		return c
+	}
+	clr, _ := colorful.MakeColor(c)
+	h, C, L := clr.Hcl()
+	hx, hy := hueVector(h, C)
+	_, _, _, alpha := c.RGBA()
+	return LChA{
+		L:     L,
+		C:     C,
+		HueX:  hx,
+		HueY:  hy,
+		Alpha: uint64(alpha >> 8),
+		Tally: 1,
+	}
+}
+
+// LChAModel converts any color.Color to an LChA color.
+var LChAModel = color.ModelFunc(accumLChAModel)
+
+// Add accumulates color.
+func (c *LChA) Add(clr color.Color) {
+	other := LChAModel.Convert(clr).(LChA)
+	c.L += other.L
+	c.C += other.C
+	c.HueX += other.HueX
+	c.HueY += other.HueY
+	c.Alpha += other.Alpha
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// a LChA is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *LChA) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies all components of an LChA by a given value.  This does not
+// change the effective color but can be used for performing weighted averages.
+func (c *LChA) Scale(w uint64) {
+	w64 := float64(w)
+	c.L *= w64
+	c.C *= w64
+	c.HueX *= w64
+	c.HueY *= w64
+	c.Alpha *= w
+	c.Tally *= w
+}
+
+// Hue returns the circular mean of the accumulated hues in degrees, in the
+// range [0, 360).  Hue returns 0 if the hues cancel out or if all accumulated
+// colors are achromatic.
+func (c LChA) Hue() float64 {
+	return vectorHue(c.HueX, c.HueY)
+}
+
+// Average averages the accumulated color of an LChA to produce an LChA with a
+// Tally of 1.  The hue vector of the result has the circular-mean hue and a
+// length equal to the mean chroma.
+func (c LChA) Average() LChA {
+	if c.Tally == 0 {
+		return LChA{}
+	}
+	tally := float64(c.Tally)
+	C := c.C / tally
+	hx, hy := hueVector(c.Hue(), C)
+	return LChA{
+		L:     c.L / tally,
+		C:     C,
+		HueX:  hx,
+		HueY:  hy,
+		Alpha: c.Alpha / c.Tally,
+		Tally: 1,
+	}
+}
+
+// Colorful averages the accumulated color of an LChA to produce a
+// colorful.Color (from the go-colorful package).
+func (c LChA) Colorful() colorful.Color {
+	avg := c.Average()
+	return colorful.Hcl(c.Hue(), avg.C, avg.L)
+}
+
+// Weight returns the tally of an LChA as a float64.
+func (c LChA) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of an LChA as an LChA with a
+// Tally of 1.
+func (c LChA) AverageColor() color.Color {
+	return c.Average()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into an
+// LChA.
+func (c *LChA) Merge(other Accumulator) error {
+	return merge(c, LChAModel, other)
+}
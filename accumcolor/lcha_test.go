@@ -0,0 +1,30 @@
+// This file defines a suite of tests for accumcolor.LChA.
+
+package accumcolor
+
+import "testing"
+
+// TestLChAValid ensures we can distinguish valid from invalid colors.
+func TestLChAValid(t *testing.T) {
+	var c LChA
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.HueX = 0.5
+	c.Tally = 1
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.C = 0.5
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.L = 1.5
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 2
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+}
@@ -0,0 +1,173 @@
+// This file defines the LinearNRGBA type and associated methods.
+
+package accumcolor
+
+import (
+	"image/color"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// A LinearNRGBA is a color.Color that supports accumulation of
+// non-alpha-premultiplied RGBA color values in linear light.  Colors are
+// decoded from sRGB before being summed and re-encoded to sRGB when averaged,
+// which makes averaging physically correct: a 50/50 mix of black and white
+// produces the same brightness as a surface that is half black and half white
+// rather than a darker gray.  An invariant maintained by all methods is that
+// either all fields are zero or each of R, G, B, and A divided by Tally
+// produces a value in its target range.
+type LinearNRGBA struct {
+	R     float64 // [0, 1]*Tally
+	G     float64 // [0, 1]*Tally
+	B     float64 // [0, 1]*Tally
+	A     uint64  // [0, 255]*Tally
+	Tally uint64
+}
+
+// Valid returns true if and only if a LinearNRGBA is valid.
+func (c LinearNRGBA) Valid() bool {
+	// The only time a Tally is allowed to be zero is if all other fields
+	// are zero.
+	if c.Tally == 0 {
+		var zero LinearNRGBA
+		return c == zero
+	}
+
+	// If Tally is nonzero, each other field divided by it must lie within
+	// its target range.
+	tally := float64(c.Tally)
+	r := c.R / tally
+	g := c.G / tally
+	b := c.B / tally
+	a := c.A / c.Tally
+	switch {
+	case r < 0.0 || r > 1.0:
+		return false
+	case g < 0.0 || g > 1.0:
+		return false
+	case b < 0.0 || b > 1.0:
+		return false
+	case a > 255:
+		return false
+	default:
+		return true
+	}
+}
+
+// RGBA converts a LinearNRGBA to alpha-premultiplied colors.
+func (c LinearNRGBA) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0 {
+		return
+	}
+	tally := float64(c.Tally)
+	clr := c.Colorful().Clamped()
+	alpha := float64(c.A) / tally / 255.0
+	r = uint32(clr.R*alpha*65535.0 + 0.5)
+	g = uint32(clr.G*alpha*65535.0 + 0.5)
+	b = uint32(clr.B*alpha*65535.0 + 0.5)
+	a = uint32(alpha*65535.0 + 0.5)
+	return
+}
+
+// accumLinearNRGBAModel is used to define a color model for LinearNRGBA.
+func accumLinearNRGBAModel(c color.Color) color.Color {
+	if _, ok := c.(LinearNRGBA); ok {
+		return c
+	}
+	clr, _ := colorful.MakeColor(c)
+	r, g, b := clr.LinearRgb()
+	_, _, _, a := c.RGBA()
+	return LinearNRGBA{
+		R:     r,
+		G:     g,
+		B:     b,
+		A:     uint64(a >> 8),
+		Tally: 1,
+	}
+}
+
+// LinearNRGBAModel converts any color.Color to a LinearNRGBA color.
+var LinearNRGBAModel = color.ModelFunc(accumLinearNRGBAModel)
+
+// Add accumulates color.
+func (c *LinearNRGBA) Add(clr color.Color) {
+	other := LinearNRGBAModel.Convert(clr).(LinearNRGBA)
+	c.R += other.R
+	c.G += other.G
+	c.B += other.B
+	c.A += other.A
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// a LinearNRGBA is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *LinearNRGBA) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies all components of a LinearNRGBA by a given value.  This
+// does not change the effective color but can be used for performing weighted
+// averages.
+func (c *LinearNRGBA) Scale(w uint64) {
+	w64 := float64(w)
+	c.R *= w64
+	c.G *= w64
+	c.B *= w64
+	c.A *= w
+	c.Tally *= w
+}
+
+// Average averages the accumulated color of a LinearNRGBA to produce a
+// LinearNRGBA with a Tally of 1.
+func (c LinearNRGBA) Average() LinearNRGBA {
+	if c.Tally == 0 {
+		return LinearNRGBA{}
+	}
+	tally := float64(c.Tally)
+	return LinearNRGBA{
+		R:     c.R / tally,
+		G:     c.G / tally,
+		B:     c.B / tally,
+		A:     c.A / c.Tally,
+		Tally: 1,
+	}
+}
+
+// Colorful averages the accumulated color of a LinearNRGBA to produce a
+// colorful.Color (from the go-colorful package), which is encoded in sRGB.
+func (c LinearNRGBA) Colorful() colorful.Color {
+	avg := c.Average()
+	return colorful.LinearRgb(avg.R, avg.G, avg.B)
+}
+
+// NRGBA averages the accumulated color of a LinearNRGBA to produce an ordinary,
+// sRGB-encoded color.NRGBA.
+func (c LinearNRGBA) NRGBA() color.NRGBA {
+	if c.Tally == 0 {
+		return color.NRGBA{}
+	}
+	r, g, b := c.Colorful().Clamped().RGB255()
+	return color.NRGBA{
+		R: r,
+		G: g,
+		B: b,
+		A: uint8(c.A / c.Tally),
+	}
+}
+
+// Weight returns the tally of a LinearNRGBA as a float64.
+func (c LinearNRGBA) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of a LinearNRGBA as a color.NRGBA.
+func (c LinearNRGBA) AverageColor() color.Color {
+	return c.NRGBA()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into a
+// LinearNRGBA.
+func (c *LinearNRGBA) Merge(other Accumulator) error {
+	return merge(c, LinearNRGBAModel, other)
+}
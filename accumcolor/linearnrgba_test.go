@@ -0,0 +1,63 @@
+// This file defines a suite of tests for accumcolor.LinearNRGBA.
+
+package accumcolor
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestLinearNRGBAValid ensures we can distinguish valid from invalid colors.
+func TestLinearNRGBAValid(t *testing.T) {
+	var c LinearNRGBA
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.G = 0.5
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 1
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.R = 1.5
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 2
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+}
+
+// TestLinearNRGBAAverage ensures that averaging black and white produces the
+// gray that emits half as much light as white rather than the sRGB midpoint.
+func TestLinearNRGBAAverage(t *testing.T) {
+	var sum LinearNRGBA
+	sum.Add(color.Black)
+	sum.Add(color.White)
+	compareFloats(t, "R", sum.R, 1.0)
+	compareFloats(t, "G", sum.G, 1.0)
+	compareFloats(t, "B", sum.B, 1.0)
+	exp := color.NRGBA{R: 188, G: 188, B: 188, A: 255}
+	if act := sum.NRGBA(); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+}
+
+// TestLinearNRGBAConvert ensures that we can convert to and from a
+// LinearNRGBA.
+func TestLinearNRGBAConvert(t *testing.T) {
+	rgba := color.RGBA{
+		R: 0x22,
+		G: 0x44,
+		B: 0x66,
+		A: 0x88,
+	}
+	lin := LinearNRGBAModel.Convert(rgba).(LinearNRGBA)
+	rgba2 := color.RGBAModel.Convert(lin).(color.RGBA)
+	if rgba != rgba2 {
+		t.Fatalf("expected RGBA = %v but saw %v", rgba, rgba2)
+	}
+}
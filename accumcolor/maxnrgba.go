@@ -0,0 +1,117 @@
+// This file defines the MaxNRGBA type and associated methods.
+
+package accumcolor
+
+import "image/color"
+
+// A MaxNRGBA is a color.Color that accumulates the per-channel maximum of a
+// set of non-alpha-premultiplied RGBA color values.  Tally counts the number
+// of colors accumulated, which distinguishes a MaxNRGBA that has accumulated
+// only black, transparent colors from one that has accumulated no colors at
+// all.  An invariant maintained by all methods is that if Tally is zero, all
+// other fields are also zero.
+type MaxNRGBA struct {
+	R     uint8
+	G     uint8
+	B     uint8
+	A     uint8
+	Tally uint64
+}
+
+// Valid returns true if and only if a MaxNRGBA is valid.
+func (c MaxNRGBA) Valid() bool {
+	// The only time a Tally is allowed to be zero is if all other fields
+	// are zero.
+	if c.Tally == 0 {
+		var zero MaxNRGBA
+		return c == zero
+	}
+	return true
+}
+
+// RGBA converts the maximum of a MaxNRGBA to alpha-premultiplied colors.
+func (c MaxNRGBA) RGBA() (r, g, b, a uint32) {
+	return c.NRGBA().RGBA()
+}
+
+// accumMaxNRGBAModel is used to define a color model for MaxNRGBA.
+func accumMaxNRGBAModel(c color.Color) color.Color {
+	if _, ok := c.(MaxNRGBA); ok {
+		return c
+	}
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return MaxNRGBA{
+		R:     nrgba.R,
+		G:     nrgba.G,
+		B:     nrgba.B,
+		A:     nrgba.A,
+		Tally: 1,
+	}
+}
+
+// MaxNRGBAModel converts any color.Color to a MaxNRGBA color.
+var MaxNRGBAModel = color.ModelFunc(accumMaxNRGBAModel)
+
+// maxUint8 returns the larger of two uint8 values.
+func maxUint8(a, b uint8) uint8 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Add accumulates color.
+func (c *MaxNRGBA) Add(clr color.Color) {
+	other := MaxNRGBAModel.Convert(clr).(MaxNRGBA)
+	switch {
+	case other.Tally == 0:
+		return
+	case c.Tally == 0:
+		*c = other
+		return
+	}
+	c.R = maxUint8(c.R, other.R)
+	c.G = maxUint8(c.G, other.G)
+	c.B = maxUint8(c.B, other.B)
+	c.A = maxUint8(c.A, other.A)
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// a MaxNRGBA is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *MaxNRGBA) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies the tally of a MaxNRGBA by a given value.  This does not
+// change the maximum color unless w is zero, in which case the MaxNRGBA is
+// reset to having accumulated no colors.
+func (c *MaxNRGBA) Scale(w uint64) {
+	if w == 0 {
+		*c = MaxNRGBA{}
+		return
+	}
+	c.Tally *= w
+}
+
+// NRGBA returns the maximum color of a MaxNRGBA as an ordinary color.NRGBA.
+func (c MaxNRGBA) NRGBA() color.NRGBA {
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+// Weight returns the tally of a MaxNRGBA as a float64.
+func (c MaxNRGBA) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the maximum color of a MaxNRGBA as a color.NRGBA.
+func (c MaxNRGBA) AverageColor() color.Color {
+	return c.NRGBA()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into a
+// MaxNRGBA.
+func (c *MaxNRGBA) Merge(other Accumulator) error {
+	return merge(c, MaxNRGBAModel, other)
+}
@@ -0,0 +1,29 @@
+// This file defines a suite of tests for accumcolor.MaxNRGBA.
+
+package accumcolor
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestMaxNRGBAAdd ensures that accumulating colors produces the per-channel
+// maximum.
+func TestMaxNRGBAAdd(t *testing.T) {
+	var c MaxNRGBA
+	c.Add(color.NRGBA{R: 200, G: 10, B: 150, A: 255})
+	c.Add(color.NRGBA{R: 100, G: 50, B: 250, A: 128})
+	c.Add(color.NRGBA{R: 150, G: 20, B: 160, A: 200})
+	exp := color.NRGBA{R: 200, G: 50, B: 250, A: 255}
+	if act := c.NRGBA(); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+	c.Scale(4)
+	if c.Tally != 12 || c.NRGBA() != exp {
+		t.Fatalf("expected %v with Tally = 12 but saw %v", exp, c)
+	}
+	c.Scale(0)
+	if c != (MaxNRGBA{}) {
+		t.Fatalf("expected %v to be zero", c)
+	}
+}
@@ -0,0 +1,117 @@
+// This file defines the MinNRGBA type and associated methods.
+
+package accumcolor
+
+import "image/color"
+
+// A MinNRGBA is a color.Color that accumulates the per-channel minimum of a
+// set of non-alpha-premultiplied RGBA color values.  Tally counts the number
+// of colors accumulated, which distinguishes a MinNRGBA that has accumulated
+// only black, transparent colors from one that has accumulated no colors at
+// all.  An invariant maintained by all methods is that if Tally is zero, all
+// other fields are also zero.
+type MinNRGBA struct {
+	R     uint8
+	G     uint8
+	B     uint8
+	A     uint8
+	Tally uint64
+}
+
+// Valid returns true if and only if a MinNRGBA is valid.
+func (c MinNRGBA) Valid() bool {
+	// The only time a Tally is allowed to be zero is if all other fields
+	// are zero.
+	if c.Tally == 0 {
+		var zero MinNRGBA
+		return c == zero
+	}
+	return true
+}
+
+// RGBA converts the minimum of a MinNRGBA to alpha-premultiplied colors.
+func (c MinNRGBA) RGBA() (r, g, b, a uint32) {
+	return c.NRGBA().RGBA()
+}
+
+// accumMinNRGBAModel is used to define a color model for MinNRGBA.
+func accumMinNRGBAModel(c color.Color) color.Color {
+	if _, ok := c.(MinNRGBA); ok {
+		return c
+	}
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return MinNRGBA{
+		R:     nrgba.R,
+		G:     nrgba.G,
+		B:     nrgba.B,
+		A:     nrgba.A,
+		Tally: 1,
+	}
+}
+
+// MinNRGBAModel converts any color.Color to a MinNRGBA color.
+var MinNRGBAModel = color.ModelFunc(accumMinNRGBAModel)
+
+// minUint8 returns the smaller of two uint8 values.
+func minUint8(a, b uint8) uint8 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Add accumulates color.
+func (c *MinNRGBA) Add(clr color.Color) {
+	other := MinNRGBAModel.Convert(clr).(MinNRGBA)
+	switch {
+	case other.Tally == 0:
+		return
+	case c.Tally == 0:
+		*c = other
+		return
+	}
+	c.R = minUint8(c.R, other.R)
+	c.G = minUint8(c.G, other.G)
+	c.B = minUint8(c.B, other.B)
+	c.A = minUint8(c.A, other.A)
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// a MinNRGBA is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *MinNRGBA) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies the tally of a MinNRGBA by a given value.  This does not
+// change the minimum color unless w is zero, in which case the MinNRGBA is
+// reset to having accumulated no colors.
+func (c *MinNRGBA) Scale(w uint64) {
+	if w == 0 {
+		*c = MinNRGBA{}
+		return
+	}
+	c.Tally *= w
+}
+
+// NRGBA returns the minimum color of a MinNRGBA as an ordinary color.NRGBA.
+func (c MinNRGBA) NRGBA() color.NRGBA {
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+// Weight returns the tally of a MinNRGBA as a float64.
+func (c MinNRGBA) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the minimum color of a MinNRGBA as a color.NRGBA.
+func (c MinNRGBA) AverageColor() color.Color {
+	return c.NRGBA()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into a
+// MinNRGBA.
+func (c *MinNRGBA) Merge(other Accumulator) error {
+	return merge(c, MinNRGBAModel, other)
+}
@@ -0,0 +1,40 @@
+// This file defines a suite of tests for accumcolor.MinNRGBA.
+
+package accumcolor
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestMinNRGBAAdd ensures that accumulating colors produces the per-channel
+// minimum.
+func TestMinNRGBAAdd(t *testing.T) {
+	var c MinNRGBA
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.Add(color.NRGBA{R: 200, G: 10, B: 150, A: 255})
+	c.Add(color.NRGBA{R: 100, G: 50, B: 250, A: 128})
+	c.Add(MinNRGBA{})
+	c.Add(color.NRGBA{R: 150, G: 20, B: 160, A: 200})
+	exp := MinNRGBA{R: 100, G: 10, B: 150, A: 128, Tally: 3}
+	if c != exp {
+		t.Fatalf("expected %v but saw %v", exp, c)
+	}
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+
+	// Ensure that an empty MinNRGBA is distinguishable from a black,
+	// transparent one.
+	var empty, black MinNRGBA
+	black.Add(color.Transparent)
+	if empty == black || black.Tally != 1 || black.NRGBA() != empty.NRGBA() {
+		t.Fatalf("expected %v and %v to differ only in tally", empty, black)
+	}
+	empty.R = 1
+	if empty.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", empty)
+	}
+}
@@ -0,0 +1,52 @@
+// This file defines the Moments type and associated methods.
+
+package accumcolor
+
+import "math"
+
+// A Moments accumulates the running mean of a single color channel and the sum
+// of squared deviations from that mean using Welford's online algorithm.  A
+// Moments does not maintain its own tally; it relies on the tally of the
+// color that contains it.
+type Moments struct {
+	Mean float64 // Mean of all values accumulated so far
+	M2   float64 // Sum of squared deviations from Mean
+}
+
+// merge combines the moments of n1 values with the moments of n2 other values,
+// following Chan et al.'s parallel variant of Welford's algorithm.  Merging a
+// single value with an M2 of zero reduces to Welford's original update rule.
+func (m *Moments) merge(n1 uint64, other Moments, n2 uint64) {
+	n := n1 + n2
+	if n == 0 {
+		return
+	}
+	delta := other.Mean - m.Mean
+	w := float64(n2) / float64(n)
+	m.Mean += delta * w
+	m.M2 += other.M2 + delta*delta*float64(n1)*w
+}
+
+// scale adjusts a Moments to represent w copies of each value it represents.
+func (m *Moments) scale(w uint64) {
+	if w == 0 {
+		*m = Moments{}
+		return
+	}
+	m.M2 *= float64(w)
+}
+
+// variance returns the unbiased sample variance of n values.  It returns 0 if
+// n is less than 2.
+func (m Moments) variance(n uint64) float64 {
+	if n < 2 {
+		return 0.0
+	}
+	return m.M2 / float64(n-1)
+}
+
+// valid returns true if and only if a Moments's mean lies in [lo, hi] and its
+// sum of squared deviations is nonnegative.
+func (m Moments) valid(lo, hi float64) bool {
+	return m.Mean >= lo && m.Mean <= hi && m.M2 >= 0.0 && !math.IsInf(m.M2, 1)
+}
@@ -2,7 +2,10 @@
 
 package accumcolor
 
-import "image/color"
+import (
+	"image/color"
+	"math/bits"
+)
 
 // An NRGBA is a color.Color that supports accumulation of
 // non-alpha-premultiplied RGBA color values.  An invariant maintained by all
@@ -82,6 +85,68 @@ func (c *NRGBA) Add(clr color.Color) {
 	c.Tally += other.Tally
 }
 
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// an NRGBA is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *NRGBA) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// checkedAdd returns a+b and a flag indicating whether the sum overflowed.
+func checkedAdd(a, b uint64) (uint64, bool) {
+	sum, carry := bits.Add64(a, b, 0)
+	return sum, carry != 0
+}
+
+// checkedMul returns a*b and a flag indicating whether the product
+// overflowed.
+func checkedMul(a, b uint64) (uint64, bool) {
+	hi, lo := bits.Mul64(a, b)
+	return lo, hi != 0
+}
+
+// AddChecked is like Add but returns ErrOverflow and leaves the NRGBA
+// unmodified if accumulating the color would overflow any component.
+func (c *NRGBA) AddChecked(clr color.Color) error {
+	other := NRGBAModel.Convert(clr).(NRGBA)
+	r, ovR := checkedAdd(c.R, other.R)
+	g, ovG := checkedAdd(c.G, other.G)
+	b, ovB := checkedAdd(c.B, other.B)
+	a, ovA := checkedAdd(c.A, other.A)
+	tally, ovT := checkedAdd(c.Tally, other.Tally)
+	if ovR || ovG || ovB || ovA || ovT {
+		return ErrOverflow
+	}
+	*c = NRGBA{R: r, G: g, B: b, A: a, Tally: tally}
+	return nil
+}
+
+// Sub removes color previously accumulated with Add.  It returns ErrUnderflow
+// if any component of the color to remove exceeds the corresponding
+// component of the NRGBA and ErrInvalid if the difference is not a valid
+// NRGBA.  In either case, the NRGBA is left unmodified.
+func (c *NRGBA) Sub(clr color.Color) error {
+	other := NRGBAModel.Convert(clr).(NRGBA)
+	switch {
+	case other.R > c.R, other.G > c.G, other.B > c.B, other.A > c.A:
+		return ErrUnderflow
+	case other.Tally > c.Tally:
+		return ErrUnderflow
+	}
+	diff := NRGBA{
+		R:     c.R - other.R,
+		G:     c.G - other.G,
+		B:     c.B - other.B,
+		A:     c.A - other.A,
+		Tally: c.Tally - other.Tally,
+	}
+	if !diff.Valid() {
+		return ErrInvalid
+	}
+	*c = diff
+	return nil
+}
+
 // Scale multiplies all components of an NRGBA by a given value.  This does not
 // change the effective color but can be used for performing weighted averages.
 func (c *NRGBA) Scale(w uint64) {
@@ -92,6 +157,21 @@ func (c *NRGBA) Scale(w uint64) {
 	c.Tally *= w
 }
 
+// ScaleChecked is like Scale but returns ErrOverflow and leaves the NRGBA
+// unmodified if scaling would overflow any component.
+func (c *NRGBA) ScaleChecked(w uint64) error {
+	r, ovR := checkedMul(c.R, w)
+	g, ovG := checkedMul(c.G, w)
+	b, ovB := checkedMul(c.B, w)
+	a, ovA := checkedMul(c.A, w)
+	tally, ovT := checkedMul(c.Tally, w)
+	if ovR || ovG || ovB || ovA || ovT {
+		return ErrOverflow
+	}
+	*c = NRGBA{R: r, G: g, B: b, A: a, Tally: tally}
+	return nil
+}
+
 // NRGBA averages the accumulated color of an NRGBA to produce an ordinary
 // color.NRGBA.
 func (c NRGBA) NRGBA() color.NRGBA {
@@ -105,3 +185,19 @@ func (c NRGBA) NRGBA() color.NRGBA {
 		A: uint8(c.A / c.Tally),
 	}
 }
+
+// Weight returns the tally of an NRGBA as a float64.
+func (c NRGBA) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of an NRGBA as a color.NRGBA.
+func (c NRGBA) AverageColor() color.Color {
+	return c.NRGBA()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into an
+// NRGBA.
+func (c *NRGBA) Merge(other Accumulator) error {
+	return merge(c, NRGBAModel, other)
+}
@@ -0,0 +1,124 @@
+// This file defines the NRGBA64 type and associated methods.
+
+package accumcolor
+
+import "image/color"
+
+// An NRGBA64 is a color.Color that supports accumulation of 16-bit
+// non-alpha-premultiplied RGBA color values.  An invariant maintained by all
+// methods is that either all fields are zero or each of R, G, B, and A divided
+// by Tally produces a value in the range [0, 65535].
+type NRGBA64 struct {
+	R     uint64
+	G     uint64
+	B     uint64
+	A     uint64
+	Tally uint64
+}
+
+// Valid returns true if and only if an NRGBA64 is valid.
+func (c NRGBA64) Valid() bool {
+	// If Tally is nonzero, each other field divided by it must lie in [0,
+	// 65535].
+	switch {
+	case c.Tally == 0:
+		// The only time a Tally is allowed to be zero is if all other
+		// fields are zero.
+		var zero NRGBA64
+		return c == zero
+	case c.R/c.Tally > 65535:
+		return false
+	case c.G/c.Tally > 65535:
+		return false
+	case c.B/c.Tally > 65535:
+		return false
+	case c.A/c.Tally > 65535:
+		return false
+	default:
+		return true
+	}
+}
+
+// RGBA converts an NRGBA64 to alpha-premultiplied colors.
+func (c NRGBA64) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0 {
+		return
+	}
+	return c.NRGBA64().RGBA()
+}
+
+// accumNRGBA64Model is used to define a color model for NRGBA64.
+func accumNRGBA64Model(c color.Color) color.Color {
+	if _, ok := c.(NRGBA64); ok {
+		return c
+	}
+	nrgba := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	return NRGBA64{
+		R:     uint64(nrgba.R),
+		G:     uint64(nrgba.G),
+		B:     uint64(nrgba.B),
+		A:     uint64(nrgba.A),
+		Tally: 1,
+	}
+}
+
+// NRGBA64Model converts any color.Color to an NRGBA64 color.
+var NRGBA64Model = color.ModelFunc(accumNRGBA64Model)
+
+// Add accumulates color.
+func (c *NRGBA64) Add(clr color.Color) {
+	other := NRGBA64Model.Convert(clr).(NRGBA64)
+	c.R += other.R
+	c.G += other.G
+	c.B += other.B
+	c.A += other.A
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// an NRGBA64 is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *NRGBA64) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies all components of an NRGBA64 by a given value.  This does
+// not change the effective color but can be used for performing weighted
+// averages.
+func (c *NRGBA64) Scale(w uint64) {
+	c.R *= w
+	c.G *= w
+	c.B *= w
+	c.A *= w
+	c.Tally *= w
+}
+
+// NRGBA64 averages the accumulated color of an NRGBA64 to produce an ordinary
+// color.NRGBA64.
+func (c NRGBA64) NRGBA64() color.NRGBA64 {
+	if c.Tally == 0 {
+		return color.NRGBA64{}
+	}
+	return color.NRGBA64{
+		R: uint16(c.R / c.Tally),
+		G: uint16(c.G / c.Tally),
+		B: uint16(c.B / c.Tally),
+		A: uint16(c.A / c.Tally),
+	}
+}
+
+// Weight returns the tally of an NRGBA64 as a float64.
+func (c NRGBA64) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of an NRGBA64 as a color.NRGBA64.
+func (c NRGBA64) AverageColor() color.Color {
+	return c.NRGBA64()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into an
+// NRGBA64.
+func (c *NRGBA64) Merge(other Accumulator) error {
+	return merge(c, NRGBA64Model, other)
+}
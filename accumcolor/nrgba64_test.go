@@ -0,0 +1,77 @@
+// This file defines a suite of tests for accumcolor.NRGBA64.
+
+package accumcolor
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestNRGBA64Valid ensures we can distinguish valid from invalid colors.
+func TestNRGBA64Valid(t *testing.T) {
+	var c NRGBA64
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.G = 12345
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 1
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c = NRGBA64{
+		R:     65535,
+		G:     65535,
+		B:     65535,
+		A:     65535,
+		Tally: 1,
+	}
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.B++
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 2
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+}
+
+// TestNRGBA64Precision ensures that averaging colors retains the low byte of
+// each channel.
+func TestNRGBA64Precision(t *testing.T) {
+	c1 := color.NRGBA64{R: 0x1234, G: 0x5678, B: 0x9abc, A: 0xffff}
+	c2 := color.NRGBA64{R: 0x1236, G: 0x567a, B: 0x9abe, A: 0xffff}
+	var sum NRGBA64
+	sum.Add(c1)
+	sum.Add(c2)
+	exp := color.NRGBA64{R: 0x1235, G: 0x5679, B: 0x9abd, A: 0xffff}
+	act := sum.NRGBA64()
+	if act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+	r, g, b, a := sum.RGBA()
+	if r != 0x1235 || g != 0x5679 || b != 0x9abd || a != 0xffff {
+		t.Fatalf("expected %v but saw {%d %d %d %d}", exp, r, g, b, a)
+	}
+}
+
+// TestNRGBA64Scale ensures that a weighted sum of colors produces the expected
+// total.
+func TestNRGBA64Scale(t *testing.T) {
+	red := NRGBA64{R: 65535, A: 65535, Tally: 1}
+	green := NRGBA64{G: 65535, A: 65535, Tally: 1}
+	red.Scale(2)
+	var sum NRGBA64
+	sum.Add(red)
+	sum.Add(green)
+	exp := color.NRGBA64{R: 43690, G: 21845, B: 0, A: 65535}
+	act := sum.NRGBA64()
+	if act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+}
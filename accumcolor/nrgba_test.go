@@ -3,6 +3,7 @@
 package accumcolor
 
 import (
+	"errors"
 	"image/color"
 	"testing"
 )
@@ -234,3 +235,65 @@ func TestNRGBAScale(t *testing.T) {
 		t.Fatalf("expected %v but saw %v", darkOrange, nrgba)
 	}
 }
+
+// TestNRGBASub confirms that Sub undoes Add and refuses to underflow.
+func TestNRGBASub(t *testing.T) {
+	c1 := color.NRGBA{R: 10, G: 20, B: 30, A: 255}
+	c2 := color.NRGBA{R: 200, G: 100, B: 0, A: 128}
+	var c NRGBA
+	c.Add(c1)
+	c.Add(c2)
+	if err := c.Sub(c2); err != nil {
+		t.Fatal(err)
+	}
+	exp := NRGBA{R: 10, G: 20, B: 30, A: 255, Tally: 1}
+	if c != exp {
+		t.Fatalf("expected %v but saw %v", exp, c)
+	}
+
+	// Removing a color that was never added should fail without
+	// modifying c.
+	if err := c.Sub(c2); !errors.Is(err, ErrUnderflow) {
+		t.Fatalf("expected ErrUnderflow but saw %v", err)
+	}
+	if c != exp {
+		t.Fatalf("expected %v but saw %v", exp, c)
+	}
+	c.Add(c1)
+	if err := c.Sub(NRGBA{Tally: 2}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid but saw %v", err)
+	}
+
+	// Removing the final color should zero out c.
+	if err := c.Sub(NRGBA{R: 20, G: 40, B: 60, A: 510, Tally: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if c != (NRGBA{}) {
+		t.Fatalf("expected %v to be zero", c)
+	}
+}
+
+// TestNRGBAChecked confirms that AddChecked and ScaleChecked detect overflow.
+func TestNRGBAChecked(t *testing.T) {
+	c := NRGBA{R: 255, G: 128, B: 0, A: 255, Tally: 1}
+	if err := c.ScaleChecked(1 << 50); err != nil {
+		t.Fatal(err)
+	}
+	before := c
+	if err := c.ScaleChecked(1 << 10); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow but saw %v", err)
+	}
+	if c != before {
+		t.Fatalf("expected %v but saw %v", before, c)
+	}
+	if err := c.AddChecked(color.NRGBA{R: 255, G: 255, B: 255, A: 255}); err != nil {
+		t.Fatal(err)
+	}
+	before = c
+	if err := c.AddChecked(NRGBA{R: ^uint64(0), Tally: 1 << 56}); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow but saw %v", err)
+	}
+	if c != before {
+		t.Fatalf("expected %v but saw %v", before, c)
+	}
+}
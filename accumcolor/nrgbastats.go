@@ -0,0 +1,177 @@
+// This file defines the NRGBAStats type and associated methods.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+)
+
+// An NRGBAStats is a color.Color that supports accumulation of
+// non-alpha-premultiplied RGBA color values while tracking not only each
+// channel's mean but also its variance.  This makes it possible to determine
+// how well an average has converged.  An invariant maintained by all methods
+// is that either all fields are zero or Tally is nonzero, each channel's mean
+// lies in the range [0, 255], and each channel's M2 is nonnegative.
+type NRGBAStats struct {
+	R     Moments
+	G     Moments
+	B     Moments
+	A     Moments
+	Tally uint64
+}
+
+// Valid returns true if and only if an NRGBAStats is valid.
+func (c NRGBAStats) Valid() bool {
+	switch {
+	case c.Tally == 0:
+		// The only time a Tally is allowed to be zero is if all other
+		// fields are zero.
+		var zero NRGBAStats
+		return c == zero
+	case !c.R.valid(0.0, 255.0):
+		return false
+	case !c.G.valid(0.0, 255.0):
+		return false
+	case !c.B.valid(0.0, 255.0):
+		return false
+	case !c.A.valid(0.0, 255.0):
+		return false
+	default:
+		return true
+	}
+}
+
+// RGBA converts the mean of an NRGBAStats to alpha-premultiplied colors.
+func (c NRGBAStats) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0 {
+		return
+	}
+	return c.NRGBA().RGBA()
+}
+
+// accumNRGBAStatsModel is used to define a color model for NRGBAStats.
+func accumNRGBAStatsModel(c color.Color) color.Color {
+	switch c := c.(type) {
+	case NRGBAStats:
+		return c
+	case NRGBA:
+		// An NRGBA records no information about variance.  We
+		// therefore treat it as Tally copies of its average color.
+		if c.Tally == 0 {
+			return NRGBAStats{}
+		}
+		tally := float64(c.Tally)
+		return NRGBAStats{
+			R:     Moments{Mean: float64(c.R) / tally},
+			G:     Moments{Mean: float64(c.G) / tally},
+			B:     Moments{Mean: float64(c.B) / tally},
+			A:     Moments{Mean: float64(c.A) / tally},
+			Tally: c.Tally,
+		}
+	}
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return NRGBAStats{
+		R:     Moments{Mean: float64(nrgba.R)},
+		G:     Moments{Mean: float64(nrgba.G)},
+		B:     Moments{Mean: float64(nrgba.B)},
+		A:     Moments{Mean: float64(nrgba.A)},
+		Tally: 1,
+	}
+}
+
+// NRGBAStatsModel converts any color.Color to an NRGBAStats color.
+var NRGBAStatsModel = color.ModelFunc(accumNRGBAStatsModel)
+
+// Add accumulates color.
+func (c *NRGBAStats) Add(clr color.Color) {
+	other := NRGBAStatsModel.Convert(clr).(NRGBAStats)
+	c.R.merge(c.Tally, other.R, other.Tally)
+	c.G.merge(c.Tally, other.G, other.Tally)
+	c.B.merge(c.Tally, other.B, other.Tally)
+	c.A.merge(c.Tally, other.A, other.Tally)
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// an NRGBAStats is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *NRGBAStats) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies the tally of an NRGBAStats by a given value as if each
+// color accumulated so far had been accumulated w times.  This does not change
+// the mean color but can be used for performing weighted averages.
+func (c *NRGBAStats) Scale(w uint64) {
+	c.R.scale(w)
+	c.G.scale(w)
+	c.B.scale(w)
+	c.A.scale(w)
+	c.Tally *= w
+}
+
+// Mean returns the mean of each channel of an NRGBAStats, in the range
+// [0, 255].
+func (c NRGBAStats) Mean() (r, g, b, a float64) {
+	return c.R.Mean, c.G.Mean, c.B.Mean, c.A.Mean
+}
+
+// Variance returns the unbiased sample variance of each channel of an
+// NRGBAStats.  All variances are zero if fewer than two colors have been
+// accumulated.
+func (c NRGBAStats) Variance() (r, g, b, a float64) {
+	r = c.R.variance(c.Tally)
+	g = c.G.variance(c.Tally)
+	b = c.B.variance(c.Tally)
+	a = c.A.variance(c.Tally)
+	return
+}
+
+// StdDev returns the sample standard deviation of each channel of an
+// NRGBAStats.
+func (c NRGBAStats) StdDev() (r, g, b, a float64) {
+	r, g, b, a = c.Variance()
+	return math.Sqrt(r), math.Sqrt(g), math.Sqrt(b), math.Sqrt(a)
+}
+
+// StandardError returns the standard error of the mean of each channel of an
+// NRGBAStats.  This indicates how far the mean is likely to lie from the true
+// mean of the distribution from which colors are drawn.
+func (c NRGBAStats) StandardError() (r, g, b, a float64) {
+	if c.Tally == 0 {
+		return
+	}
+	r, g, b, a = c.StdDev()
+	sqrtN := math.Sqrt(float64(c.Tally))
+	return r / sqrtN, g / sqrtN, b / sqrtN, a / sqrtN
+}
+
+// NRGBA rounds the mean of an NRGBAStats to produce an ordinary color.NRGBA.
+func (c NRGBAStats) NRGBA() color.NRGBA {
+	if c.Tally == 0 {
+		return color.NRGBA{}
+	}
+	return color.NRGBA{
+		R: uint8(c.R.Mean + 0.5),
+		G: uint8(c.G.Mean + 0.5),
+		B: uint8(c.B.Mean + 0.5),
+		A: uint8(c.A.Mean + 0.5),
+	}
+}
+
+// Weight returns the tally of an NRGBAStats as a float64.
+func (c NRGBAStats) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the mean color of an NRGBAStats as a color.NRGBA.
+func (c NRGBAStats) AverageColor() color.Color {
+	return c.NRGBA()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into an
+// NRGBAStats.
+func (c *NRGBAStats) Merge(other Accumulator) error {
+	return merge(c, NRGBAStatsModel, other)
+}
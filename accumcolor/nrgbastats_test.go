@@ -0,0 +1,108 @@
+// This file defines a suite of tests for accumcolor.NRGBAStats.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// TestNRGBAStatsValid ensures we can distinguish valid from invalid colors.
+func TestNRGBAStatsValid(t *testing.T) {
+	var c NRGBAStats
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.G.Mean = 12.0
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 1
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.B.M2 = -1.0
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.B.M2 = 1.0
+	c.A.Mean = 256.0
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+}
+
+// TestNRGBAStatsVariance ensures that accumulating colors produces the
+// expected mean, variance, standard deviation, and standard error.
+func TestNRGBAStatsVariance(t *testing.T) {
+	// Accumulate a set of values whose mean is 5 and whose sum of squared
+	// deviations is 32.
+	var c NRGBAStats
+	for _, v := range []uint8{2, 4, 4, 4, 5, 5, 7, 9} {
+		c.Add(color.NRGBA{R: v, G: v * 2, B: 100, A: 255})
+	}
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	r, g, b, a := c.Mean()
+	compareFloats(t, "mean R", r, 5.0)
+	compareFloats(t, "mean G", g, 10.0)
+	compareFloats(t, "mean B", b, 100.0)
+	compareFloats(t, "mean A", a, 255.0)
+	r, g, b, a = c.Variance()
+	compareFloats(t, "variance R", r, 32.0/7.0)
+	compareFloats(t, "variance G", g, 128.0/7.0)
+	compareFloats(t, "variance B", b, 0.0)
+	compareFloats(t, "variance A", a, 0.0)
+	r, _, _, _ = c.StdDev()
+	compareFloats(t, "standard deviation R", r, math.Sqrt(32.0/7.0))
+	r, _, _, _ = c.StandardError()
+	compareFloats(t, "standard error R", r, math.Sqrt(32.0/7.0/8.0))
+	exp := color.NRGBA{R: 5, G: 10, B: 100, A: 255}
+	if act := c.NRGBA(); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+}
+
+// TestNRGBAStatsMerge ensures that merging two partial accumulations produces
+// the same result as a single accumulation.
+func TestNRGBAStatsMerge(t *testing.T) {
+	var all, part1, part2 NRGBAStats
+	for i := 0; i < 50; i++ {
+		clr := color.NRGBA{
+			R: uint8(i * 5),
+			G: uint8((i * i) % 256),
+			B: uint8(255 - i),
+			A: uint8(200 + i),
+		}
+		all.Add(clr)
+		if i%3 == 0 {
+			part1.Add(clr)
+		} else {
+			part2.Add(clr)
+		}
+	}
+	part1.Add(part2)
+	if part1.Tally != all.Tally {
+		t.Fatalf("expected Tally = %d but saw %d", all.Tally, part1.Tally)
+	}
+	r1, g1, b1, a1 := all.Variance()
+	r2, g2, b2, a2 := part1.Variance()
+	compareFloats(t, "variance R", r2, r1)
+	compareFloats(t, "variance G", g2, g1)
+	compareFloats(t, "variance B", b2, b1)
+	compareFloats(t, "variance A", a2, a1)
+
+	// Scaling should preserve the mean and approximately preserve the
+	// variance.
+	part2 = all
+	part2.Scale(1000)
+	r2, _, _, _ = part2.Variance()
+	if math.Abs(r2-r1)/r1 > 0.05 {
+		t.Fatalf("expected variance R to be near %v but saw %v", r1, r2)
+	}
+	if all.NRGBA() != part2.NRGBA() {
+		t.Fatalf("expected %v but saw %v", all.NRGBA(), part2.NRGBA())
+	}
+}
@@ -0,0 +1,181 @@
+// This file defines the OkLabA type and associated methods.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// An OkLabA is a color.Color that supports accumulation of Oklab color values.
+// An invariant maintained by all methods is that either all fields are zero or
+// each of L, A, B, and Alpha divided by Tally produces a value in its target
+// range.
+type OkLabA struct {
+	L     float64 // [0, 1]*Tally
+	A     float64 // [-0.5, 0.5]*Tally
+	B     float64 // [-0.5, 0.5]*Tally
+	Alpha uint64  // [0, 255]*Tally
+	Tally uint64
+}
+
+// linearRGBToOkLab converts linear-light sRGB to Oklab.  The coefficients are
+// taken from Björn Ottosson's definition of the Oklab color space.
+func linearRGBToOkLab(r, g, b float64) (L, A, B float64) {
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+	L = 0.2104542553*l + 0.7936177850*m - 0.0040720468*s
+	A = 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	B = 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+	return
+}
+
+// okLabToLinearRGB converts Oklab to linear-light sRGB.  It is the inverse of
+// linearRGBToOkLab.
+func okLabToLinearRGB(L, A, B float64) (r, g, b float64) {
+	l := L + 0.3963377774*A + 0.2158037573*B
+	m := L - 0.1055613458*A - 0.0638541728*B
+	s := L - 0.0894841775*A - 1.2914855480*B
+	l, m, s = l*l*l, m*m*m, s*s*s
+	r = 4.0767416621*l - 3.3077115913*m + 0.2309699292*s
+	g = -1.2684380046*l + 2.6097574011*m - 0.3413193965*s
+	b = -0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+	return
+}
+
+// Valid returns true if and only if an OkLabA is valid.
+func (c OkLabA) Valid() bool {
+	// The only time a Tally is allowed to be zero is if all other fields
+	// are zero.
+	if c.Tally == 0 {
+		var zero OkLabA
+		return c == zero
+	}
+
+	// If Tally is nonzero, each other field divided by it must lie within
+	// its target range.
+	tally := float64(c.Tally)
+	L := c.L / tally
+	a := c.A / tally
+	b := c.B / tally
+	alpha := c.Alpha / c.Tally
+	switch {
+	case L < 0.0 || L > 1.0:
+		return false
+	case a < -0.5 || a > 0.5:
+		return false
+	case b < -0.5 || b > 0.5:
+		return false
+	case alpha > 255:
+		return false
+	default:
+		return true
+	}
+}
+
+// RGBA converts an OkLabA to alpha-premultiplied colors.
+func (c OkLabA) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0 {
+		return
+	}
+	tally := float64(c.Tally)
+	clr := c.Colorful().Clamped()
+	alpha := float64(c.Alpha) / tally / 255.0
+	r = uint32(clr.R*alpha*65535.0 + 0.5)
+	g = uint32(clr.G*alpha*65535.0 + 0.5)
+	b = uint32(clr.B*alpha*65535.0 + 0.5)
+	a = uint32(alpha*65535.0 + 0.5)
+	return
+}
+
+// accumOkLabAModel is used to define a color model for OkLabA.
+func accumOkLabAModel(c color.Color) color.Color {
+	if _, ok := c.(OkLabA); ok {
+		return c
+	}
+	clr, _ := colorful.MakeColor(c)
+	L, a, b := linearRGBToOkLab(clr.LinearRgb())
+	_, _, _, alpha := c.RGBA()
+	return OkLabA{
+		L:     L,
+		A:     a,
+		B:     b,
+		Alpha: uint64(alpha >> 8),
+		Tally: 1,
+	}
+}
+
+// OkLabAModel converts any color.Color to an OkLabA color.
+var OkLabAModel = color.ModelFunc(accumOkLabAModel)
+
+// Add accumulates color.
+func (c *OkLabA) Add(clr color.Color) {
+	other := OkLabAModel.Convert(clr).(OkLabA)
+	c.L += other.L
+	c.A += other.A
+	c.B += other.B
+	c.Alpha += other.Alpha
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// an OkLabA is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *OkLabA) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies all components of an OkLabA by a given value.  This does
+// not change the effective color but can be used for performing weighted
+// averages.
+func (c *OkLabA) Scale(w uint64) {
+	w64 := float64(w)
+	c.L *= w64
+	c.A *= w64
+	c.B *= w64
+	c.Alpha *= w
+	c.Tally *= w
+}
+
+// Average averages the accumulated color of an OkLabA to produce an OkLabA
+// with a Tally of 1.
+func (c OkLabA) Average() OkLabA {
+	if c.Tally == 0 {
+		return OkLabA{}
+	}
+	tally := float64(c.Tally)
+	return OkLabA{
+		L:     c.L / tally,
+		A:     c.A / tally,
+		B:     c.B / tally,
+		Alpha: c.Alpha / c.Tally,
+		Tally: 1,
+	}
+}
+
+// Colorful averages the accumulated color of an OkLabA to produce a
+// colorful.Color (from the go-colorful package).
+func (c OkLabA) Colorful() colorful.Color {
+	avg := c.Average()
+	return colorful.LinearRgb(okLabToLinearRGB(avg.L, avg.A, avg.B))
+}
+
+// Weight returns the tally of an OkLabA as a float64.
+func (c OkLabA) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of an OkLabA as an OkLabA with a
+// Tally of 1.
+func (c OkLabA) AverageColor() color.Color {
+	return c.Average()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into an
+// OkLabA.
+func (c *OkLabA) Merge(other Accumulator) error {
+	return merge(c, OkLabAModel, other)
+}
@@ -0,0 +1,87 @@
+// This file defines a suite of tests for accumcolor.OkLabA.
+
+package accumcolor
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestOkLabAValid ensures we can distinguish valid from invalid colors.
+func TestOkLabAValid(t *testing.T) {
+	var c OkLabA
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.A = 0.25
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 1
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.B = -0.75
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 2
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+}
+
+// TestOkLabAConvert ensures that we can convert to and from an OkLabA.
+func TestOkLabAConvert(t *testing.T) {
+	// White should map to L = 1, a = b = 0.
+	white := OkLabAModel.Convert(color.White).(OkLabA)
+	compareFloats(t, "L", white.L, 1.0)
+	compareFloats(t, "A", white.A, 0.0)
+	compareFloats(t, "B", white.B, 0.0)
+
+	// Arbitrary colors should make the round trip unscathed.
+	rgba := color.RGBA{
+		R: 0x22,
+		G: 0x44,
+		B: 0x66,
+		A: 0x88,
+	}
+	oklaba := OkLabAModel.Convert(rgba).(OkLabA)
+	rgba2 := color.RGBAModel.Convert(oklaba).(color.RGBA)
+	if rgba != rgba2 {
+		t.Fatalf("expected RGBA = %v but saw %v", rgba, rgba2)
+	}
+}
+
+// TestOkLabAAverage ensures that averaging colors produces the expected
+// result.
+func TestOkLabAAverage(t *testing.T) {
+	// Average (2*red + 1*green + 0*blue)/3.
+	convertRGB := func(r, g, b uint8) OkLabA {
+		clr := color.RGBA{R: r, G: g, B: b, A: 255}
+		return OkLabAModel.Convert(clr).(OkLabA)
+	}
+	red := convertRGB(255, 0, 0)
+	green := convertRGB(0, 255, 0)
+	blue := convertRGB(0, 0, 255)
+	red.Scale(2)
+	blue.Scale(0)
+	var sum OkLabA
+	sum.Add(red)
+	sum.Add(green)
+	sum.Add(blue)
+	if !sum.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", sum)
+	}
+	avg := sum.Average()
+	if avg.Tally != 1 || avg.Alpha != 255 {
+		t.Fatalf("expected Tally = 1 and Alpha = 255 but saw %v", avg)
+	}
+
+	// Ensure we wound up with an orange.
+	nrgba := color.NRGBAModel.Convert(avg).(color.NRGBA)
+	orange := color.NRGBA{R: 229, G: 135, B: 0, A: 255}
+	if nrgba != orange {
+		t.Fatalf("expected %v but saw %v", orange, nrgba)
+	}
+}
@@ -0,0 +1,154 @@
+// This file defines the RGBA64 type and associated methods.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math/bits"
+)
+
+// An RGBA64 is a color.Color that supports accumulation of 16-bit
+// alpha-premultiplied RGBA color values.  Because each color channel is
+// weighted by its alpha, transparent colors contribute little or nothing to
+// the averaged color, only to the averaged alpha.  An invariant maintained by
+// all methods is that either all fields are zero or each of R, G, B, and A
+// divided by Tally produces a value in the range [0, 65535] and none of R, G,
+// and B exceeds A.
+type RGBA64 struct {
+	R     uint64
+	G     uint64
+	B     uint64
+	A     uint64
+	Tally uint64
+}
+
+// Valid returns true if and only if an RGBA64 is valid.
+func (c RGBA64) Valid() bool {
+	// If Tally is nonzero, each other field divided by it must lie in [0,
+	// 65535].  Being alpha-premultiplied, no color channel can exceed the
+	// alpha channel.
+	switch {
+	case c.Tally == 0:
+		// The only time a Tally is allowed to be zero is if all other
+		// fields are zero.
+		var zero RGBA64
+		return c == zero
+	case c.A/c.Tally > 65535:
+		return false
+	case c.R > c.A:
+		return false
+	case c.G > c.A:
+		return false
+	case c.B > c.A:
+		return false
+	default:
+		return true
+	}
+}
+
+// RGBA returns the average of an RGBA64's alpha-premultiplied colors.
+func (c RGBA64) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0 {
+		return
+	}
+	r = uint32(c.R / c.Tally)
+	g = uint32(c.G / c.Tally)
+	b = uint32(c.B / c.Tally)
+	a = uint32(c.A / c.Tally)
+	return
+}
+
+// accumRGBA64Model is used to define a color model for RGBA64.
+func accumRGBA64Model(c color.Color) color.Color {
+	if _, ok := c.(RGBA64); ok {
+		return c
+	}
+	r, g, b, a := c.RGBA()
+	return RGBA64{
+		R:     uint64(r),
+		G:     uint64(g),
+		B:     uint64(b),
+		A:     uint64(a),
+		Tally: 1,
+	}
+}
+
+// RGBA64Model converts any color.Color to an RGBA64 color.
+var RGBA64Model = color.ModelFunc(accumRGBA64Model)
+
+// Add accumulates color.
+func (c *RGBA64) Add(clr color.Color) {
+	other := RGBA64Model.Convert(clr).(RGBA64)
+	c.R += other.R
+	c.G += other.G
+	c.B += other.B
+	c.A += other.A
+	c.Tally += other.Tally
+}
+
+// ScaleWeight is like Scale but takes a float64 weight.  Because the tally of
+// an RGBA64 is an integer, ScaleWeight returns ErrWeight, leaving the color
+// unchanged, unless w is a nonnegative integer.
+func (c *RGBA64) ScaleWeight(w float64) error {
+	return scaleInteger(c, w)
+}
+
+// Scale multiplies all components of an RGBA64 by a given value.  This does
+// not change the effective color but can be used for performing weighted
+// averages.
+func (c *RGBA64) Scale(w uint64) {
+	c.R *= w
+	c.G *= w
+	c.B *= w
+	c.A *= w
+	c.Tally *= w
+}
+
+// RGBA64 averages the accumulated color of an RGBA64 to produce an ordinary,
+// alpha-premultiplied color.RGBA64.
+func (c RGBA64) RGBA64() color.RGBA64 {
+	r, g, b, a := c.RGBA()
+	return color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: uint16(a)}
+}
+
+// unpremultiply divides an alpha-premultiplied channel sum by an alpha sum and
+// scales the result to [0, 65535].  Performing the division on sums rather
+// than on averages preserves precision.
+func unpremultiply(v, a uint64) uint16 {
+	if v >= a {
+		return 0xffff
+	}
+	hi, lo := bits.Mul64(v, 0xffff)
+	q, _ := bits.Div64(hi, lo, a)
+	return uint16(q)
+}
+
+// NRGBA64 averages the accumulated color of an RGBA64 and un-premultiplies it
+// to produce an ordinary color.NRGBA64.
+func (c RGBA64) NRGBA64() color.NRGBA64 {
+	if c.A == 0 {
+		return color.NRGBA64{}
+	}
+	return color.NRGBA64{
+		R: unpremultiply(c.R, c.A),
+		G: unpremultiply(c.G, c.A),
+		B: unpremultiply(c.B, c.A),
+		A: uint16(c.A / c.Tally),
+	}
+}
+
+// Weight returns the tally of an RGBA64 as a float64.
+func (c RGBA64) Weight() float64 {
+	return float64(c.Tally)
+}
+
+// AverageColor returns the average color of an RGBA64 as a color.RGBA64.
+func (c RGBA64) AverageColor() color.Color {
+	return c.RGBA64()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into an
+// RGBA64.
+func (c *RGBA64) Merge(other Accumulator) error {
+	return merge(c, RGBA64Model, other)
+}
@@ -0,0 +1,55 @@
+// This file defines a suite of tests for accumcolor.RGBA64.
+
+package accumcolor
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestRGBA64Valid ensures we can distinguish valid from invalid colors.
+func TestRGBA64Valid(t *testing.T) {
+	var c RGBA64
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.A = 100
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 1
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.R = 101
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c = RGBA64{R: 65535, G: 65535, B: 65535, A: 65536, Tally: 1}
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 2
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+}
+
+// TestRGBA64Transparent ensures that fully transparent colors do not tint the
+// average color.
+func TestRGBA64Transparent(t *testing.T) {
+	var sum RGBA64
+	sum.Add(color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+	sum.Add(color.Transparent)
+	if !sum.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", sum)
+	}
+	exp := color.NRGBA64{R: 200 * 257, G: 100 * 257, B: 50 * 257, A: 0x7fff}
+	if act := sum.NRGBA64(); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+	expPre := color.RGBA64{R: 100 * 257, G: 50 * 257, B: 25 * 257, A: 0x7fff}
+	if act := sum.RGBA64(); act != expPre {
+		t.Fatalf("expected %v but saw %v", expPre, act)
+	}
+}
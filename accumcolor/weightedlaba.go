@@ -0,0 +1,161 @@
+// This file defines the WeightedLabA type and associated methods.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// A WeightedLabA is a color.Color that supports accumulation of CIE L*a*b*
+// color values with real-valued weights.  It is analogous to a LabA but stores
+// its alpha sum and its tally as floating-point numbers.  An invariant
+// maintained by all methods is that either all fields are zero or Tally is
+// positive and each of L, A, B, and Alpha divided by Tally produces a value in
+// its target range.
+type WeightedLabA struct {
+	L     float64 // [0, 1]*Tally
+	A     float64 // [-1, 1]*Tally
+	B     float64 // [-1, 1]*Tally
+	Alpha float64 // [0, 255]*Tally
+	Tally float64
+}
+
+// Valid returns true if and only if a WeightedLabA is valid.
+func (c WeightedLabA) Valid() bool {
+	switch {
+	case c.Tally == 0.0:
+		// The only time a Tally is allowed to be zero is if all other
+		// fields are zero.
+		var zero WeightedLabA
+		return c == zero
+	case !(c.Tally > 0.0) || math.IsInf(c.Tally, 1):
+		return false // Negative, NaN, or infinite Tally
+	case !validWeightedChannel(c.L, c.Tally, 1.0):
+		return false
+	case !validWeightedChannel(c.A+c.Tally, c.Tally, 2.0):
+		return false
+	case !validWeightedChannel(c.B+c.Tally, c.Tally, 2.0):
+		return false
+	case !validWeightedChannel(c.Alpha, c.Tally, 255.0):
+		return false
+	default:
+		return true
+	}
+}
+
+// RGBA converts a WeightedLabA to alpha-premultiplied colors.
+func (c WeightedLabA) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0.0 {
+		return
+	}
+	clr := c.Colorful().Clamped()
+	alpha := math.Min(c.Alpha/c.Tally/255.0, 1.0)
+	r = uint32(clr.R*alpha*65535.0 + 0.5)
+	g = uint32(clr.G*alpha*65535.0 + 0.5)
+	b = uint32(clr.B*alpha*65535.0 + 0.5)
+	a = uint32(alpha*65535.0 + 0.5)
+	return
+}
+
+// accumWeightedLabAModel is used to define a color model for WeightedLabA.
+func accumWeightedLabAModel(c color.Color) color.Color {
+	switch c := c.(type) {
+	case WeightedLabA:
+		return c
+	case LabA:
+		return WeightedLabA{
+			L:     c.L,
+			A:     c.A,
+			B:     c.B,
+			Alpha: float64(c.Alpha),
+			Tally: float64(c.Tally),
+		}
+	}
+	clr, _ := colorful.MakeColor(c)
+	L, a, b := clr.Lab()
+	_, _, _, alpha := c.RGBA()
+	return WeightedLabA{
+		L:     L,
+		A:     a,
+		B:     b,
+		Alpha: float64(alpha >> 8),
+		Tally: 1.0,
+	}
+}
+
+// WeightedLabAModel converts any color.Color to a WeightedLabA color.
+var WeightedLabAModel = color.ModelFunc(accumWeightedLabAModel)
+
+// Add accumulates color.
+func (c *WeightedLabA) Add(clr color.Color) {
+	c.AddWeighted(clr, 1.0)
+}
+
+// AddWeighted accumulates color with a given nonnegative weight.
+func (c *WeightedLabA) AddWeighted(clr color.Color, w float64) {
+	other := WeightedLabAModel.Convert(clr).(WeightedLabA)
+	c.L += other.L * w
+	c.A += other.A * w
+	c.B += other.B * w
+	c.Alpha += other.Alpha * w
+	c.Tally += other.Tally * w
+}
+
+// Scale multiplies all components of a WeightedLabA by a given nonnegative
+// value.  This does not change the effective color but can be used for
+// performing weighted averages.
+func (c *WeightedLabA) Scale(w float64) {
+	c.L *= w
+	c.A *= w
+	c.B *= w
+	c.Alpha *= w
+	c.Tally *= w
+}
+
+// ScaleWeight is like Scale but returns ErrWeight, leaving the color
+// unchanged, if w is negative, NaN, or infinite.
+func (c *WeightedLabA) ScaleWeight(w float64) error {
+	return scaleReal(c, w)
+}
+
+// Average averages the accumulated color of a WeightedLabA to produce a
+// WeightedLabA with a Tally of 1.
+func (c WeightedLabA) Average() WeightedLabA {
+	if c.Tally == 0.0 {
+		return WeightedLabA{}
+	}
+	return WeightedLabA{
+		L:     c.L / c.Tally,
+		A:     c.A / c.Tally,
+		B:     c.B / c.Tally,
+		Alpha: c.Alpha / c.Tally,
+		Tally: 1.0,
+	}
+}
+
+// Colorful averages the accumulated color of a WeightedLabA to produce a
+// colorful.Color (from the go-colorful package).
+func (c WeightedLabA) Colorful() colorful.Color {
+	avg := c.Average()
+	return colorful.Lab(avg.L, avg.A, avg.B)
+}
+
+// Weight returns the tally of a WeightedLabA.
+func (c WeightedLabA) Weight() float64 {
+	return c.Tally
+}
+
+// AverageColor returns the average color of a WeightedLabA as a WeightedLabA
+// with a Tally of 1.
+func (c WeightedLabA) AverageColor() color.Color {
+	return c.Average()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into a
+// WeightedLabA.
+func (c *WeightedLabA) Merge(other Accumulator) error {
+	return merge(c, WeightedLabAModel, other)
+}
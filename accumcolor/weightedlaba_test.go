@@ -0,0 +1,64 @@
+// This file defines a suite of tests for accumcolor.WeightedLabA.
+
+package accumcolor
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestWeightedLabAValid ensures we can distinguish valid from invalid colors.
+func TestWeightedLabAValid(t *testing.T) {
+	var c WeightedLabA
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.A = -0.5
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 0.5
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.B = 0.75
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 0.75
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.Tally = -0.75
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+}
+
+// TestWeightedLabAAddWeighted ensures that fractional weights produce the
+// same average as the equivalent integer weights.
+func TestWeightedLabAAddWeighted(t *testing.T) {
+	// Average (2*red + 1*green)/3 using integer weights.
+	convertRGB := func(r, g, b uint8) LabA {
+		clr := color.RGBA{R: r, G: g, B: b, A: 255}
+		return LabAModel.Convert(clr).(LabA)
+	}
+	red := convertRGB(255, 0, 0)
+	green := convertRGB(0, 255, 0)
+	var isum LabA
+	red.Scale(2)
+	isum.Add(red)
+	isum.Add(green)
+	exp := isum.Average()
+
+	// Average (2/3*red + 1/3*green) using fractional weights.
+	var wsum WeightedLabA
+	wsum.AddWeighted(convertRGB(255, 0, 0), 2.0/3.0)
+	wsum.AddWeighted(convertRGB(0, 255, 0), 1.0/3.0)
+	act := wsum.Average()
+	compareFloats(t, "L", act.L, exp.L)
+	compareFloats(t, "A", act.A, exp.A)
+	compareFloats(t, "B", act.B, exp.B)
+	compareFloats(t, "Alpha", act.Alpha, float64(exp.Alpha))
+	compareFloats(t, "Tally", wsum.Tally, 1.0)
+}
@@ -0,0 +1,152 @@
+// This file defines the WeightedNRGBA type and associated methods.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+)
+
+// A WeightedNRGBA is a color.Color that supports accumulation of
+// non-alpha-premultiplied RGBA color values with real-valued weights.  It is
+// analogous to an NRGBA but stores its sums and its tally as floating-point
+// numbers, which supports fractional weights such as those produced by
+// bilinear splatting, Gaussian kernels, and anti-aliased rendering.  An
+// invariant maintained by all methods is that either all fields are zero or
+// Tally is positive and each of R, G, B, and A divided by Tally produces a
+// value in the range [0, 255].
+type WeightedNRGBA struct {
+	R     float64
+	G     float64
+	B     float64
+	A     float64
+	Tally float64
+}
+
+// validWeightedChannel returns true if and only if a weighted channel sum
+// divided by a positive tally lies in [0, max], allowing for a small amount of
+// round-off error.
+func validWeightedChannel(v, tally, max float64) bool {
+	const eps = 1e-9
+	return v >= -eps*tally && v <= max*tally*(1.0+eps)
+}
+
+// Valid returns true if and only if a WeightedNRGBA is valid.
+func (c WeightedNRGBA) Valid() bool {
+	switch {
+	case c.Tally == 0.0:
+		// The only time a Tally is allowed to be zero is if all other
+		// fields are zero.
+		var zero WeightedNRGBA
+		return c == zero
+	case !(c.Tally > 0.0) || math.IsInf(c.Tally, 1):
+		return false // Negative, NaN, or infinite Tally
+	case !validWeightedChannel(c.R, c.Tally, 255.0):
+		return false
+	case !validWeightedChannel(c.G, c.Tally, 255.0):
+		return false
+	case !validWeightedChannel(c.B, c.Tally, 255.0):
+		return false
+	case !validWeightedChannel(c.A, c.Tally, 255.0):
+		return false
+	default:
+		return true
+	}
+}
+
+// RGBA converts a WeightedNRGBA to alpha-premultiplied colors.
+func (c WeightedNRGBA) RGBA() (r, g, b, a uint32) {
+	if c.Tally == 0.0 {
+		return
+	}
+	return c.NRGBA().RGBA()
+}
+
+// accumWeightedNRGBAModel is used to define a color model for WeightedNRGBA.
+func accumWeightedNRGBAModel(c color.Color) color.Color {
+	switch c := c.(type) {
+	case WeightedNRGBA:
+		return c
+	case NRGBA:
+		return WeightedNRGBA{
+			R:     float64(c.R),
+			G:     float64(c.G),
+			B:     float64(c.B),
+			A:     float64(c.A),
+			Tally: float64(c.Tally),
+		}
+	}
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return WeightedNRGBA{
+		R:     float64(nrgba.R),
+		G:     float64(nrgba.G),
+		B:     float64(nrgba.B),
+		A:     float64(nrgba.A),
+		Tally: 1.0,
+	}
+}
+
+// WeightedNRGBAModel converts any color.Color to a WeightedNRGBA color.
+var WeightedNRGBAModel = color.ModelFunc(accumWeightedNRGBAModel)
+
+// Add accumulates color.
+func (c *WeightedNRGBA) Add(clr color.Color) {
+	c.AddWeighted(clr, 1.0)
+}
+
+// AddWeighted accumulates color with a given nonnegative weight.
+func (c *WeightedNRGBA) AddWeighted(clr color.Color, w float64) {
+	other := WeightedNRGBAModel.Convert(clr).(WeightedNRGBA)
+	c.R += other.R * w
+	c.G += other.G * w
+	c.B += other.B * w
+	c.A += other.A * w
+	c.Tally += other.Tally * w
+}
+
+// Scale multiplies all components of a WeightedNRGBA by a given nonnegative
+// value.  This does not change the effective color but can be used for
+// performing weighted averages.
+func (c *WeightedNRGBA) Scale(w float64) {
+	c.R *= w
+	c.G *= w
+	c.B *= w
+	c.A *= w
+	c.Tally *= w
+}
+
+// ScaleWeight is like Scale but returns ErrWeight, leaving the color
+// unchanged, if w is negative, NaN, or infinite.
+func (c *WeightedNRGBA) ScaleWeight(w float64) error {
+	return scaleReal(c, w)
+}
+
+// NRGBA averages the accumulated color of a WeightedNRGBA to produce an
+// ordinary color.NRGBA.  Each channel is rounded to the nearest integer.
+func (c WeightedNRGBA) NRGBA() color.NRGBA {
+	if c.Tally == 0.0 {
+		return color.NRGBA{}
+	}
+	return color.NRGBA{
+		R: uint8(c.R/c.Tally + 0.5),
+		G: uint8(c.G/c.Tally + 0.5),
+		B: uint8(c.B/c.Tally + 0.5),
+		A: uint8(c.A/c.Tally + 0.5),
+	}
+}
+
+// Weight returns the tally of a WeightedNRGBA.
+func (c WeightedNRGBA) Weight() float64 {
+	return c.Tally
+}
+
+// AverageColor returns the average color of a WeightedNRGBA as a color.NRGBA.
+func (c WeightedNRGBA) AverageColor() color.Color {
+	return c.NRGBA()
+}
+
+// Merge accumulates everything accumulated by another Accumulator into a
+// WeightedNRGBA.
+func (c *WeightedNRGBA) Merge(other Accumulator) error {
+	return merge(c, WeightedNRGBAModel, other)
+}
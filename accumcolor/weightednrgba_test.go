@@ -0,0 +1,69 @@
+// This file defines a suite of tests for accumcolor.WeightedNRGBA.
+
+package accumcolor
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// TestWeightedNRGBAValid ensures we can distinguish valid from invalid colors.
+func TestWeightedNRGBAValid(t *testing.T) {
+	var c WeightedNRGBA
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.G = 12.5
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 0.25
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.R = 64.0
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+	c.Tally = 0.5
+	if !c.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", c)
+	}
+	c.Tally = math.NaN()
+	if c.Valid() {
+		t.Fatalf("expected %v to be invalid, but it is deemed valid", c)
+	}
+}
+
+// TestWeightedNRGBAAddWeighted ensures that fractional weights produce the
+// expected average.
+func TestWeightedNRGBAAddWeighted(t *testing.T) {
+	// Average 0.75*red + 0.25*blue with a total weight of 1.
+	var sum WeightedNRGBA
+	sum.AddWeighted(color.NRGBA{R: 255, A: 255}, 0.75)
+	sum.AddWeighted(color.NRGBA{B: 255, A: 255}, 0.25)
+	compareFloats(t, "Tally", sum.Tally, 1.0)
+	exp := color.NRGBA{R: 191, G: 0, B: 64, A: 255}
+	if act := sum.NRGBA(); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+
+	// Scaling should not change the average.
+	sum.Scale(0.001)
+	if !sum.Valid() {
+		t.Fatalf("expected %v to be valid, but it is deemed invalid", sum)
+	}
+	if act := sum.NRGBA(); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+
+	// Integer-tallied colors should retain their tally.
+	sum = WeightedNRGBA{}
+	sum.AddWeighted(NRGBA{R: 30, G: 60, B: 90, A: 120, Tally: 3}, 0.5)
+	compareFloats(t, "Tally", sum.Tally, 1.5)
+	exp = color.NRGBA{R: 10, G: 20, B: 30, A: 40}
+	if act := sum.NRGBA(); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+}
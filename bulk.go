@@ -0,0 +1,149 @@
+// This file defines helper functions for reading whole images efficiently.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+)
+
+// clipImage clips a destination rectangle r to both the destination bounds
+// and the bounds of a source image, where the source point sp corresponds to
+// r.Min.  It returns the clipped rectangle and the correspondingly adjusted
+// source point.
+func clipImage(dst, r image.Rectangle, src image.Image, sp image.Point) (image.Rectangle, image.Point) {
+	orig := r.Min
+	r = r.Intersect(dst)
+	r = r.Intersect(src.Bounds().Add(orig.Sub(sp)))
+	return r, sp.Add(r.Min.Sub(orig))
+}
+
+// processBackward reports whether copying the rectangle of src that starts at
+// sp to the rectangle r of dst must process pixels from the bottom right to
+// the top left.  As in the standard library's draw.Draw, this is the case when
+// dst and src are the same image and the source region lies above or to the
+// left of an overlapping destination region, so processing pixels in the usual
+// order would read pixels that have already been written.
+func processBackward(dst, src image.Image, r image.Rectangle, sp image.Point) bool {
+	return dst == src &&
+		r.Overlaps(r.Add(sp.Sub(r.Min))) &&
+		(sp.Y < r.Min.Y || (sp.Y == r.Min.Y && sp.X < r.Min.X))
+}
+
+// forEachPoint invokes a function on each point (x, y) in a rectangle, either
+// in the usual, top-left to bottom-right order or, if backward is true, in the
+// reverse order.
+func forEachPoint(r image.Rectangle, backward bool, f func(x, y int)) {
+	if !backward {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				f(x, y)
+			}
+		}
+		return
+	}
+	for y := r.Max.Y - 1; y >= r.Min.Y; y-- {
+		for x := r.Max.X - 1; x >= r.Min.X; x-- {
+			f(x, y)
+		}
+	}
+}
+
+// rgba64 converts the alpha-premultiplied colors returned by an RGBA method
+// to a color.RGBA64.
+func rgba64(r, g, b, a uint32) color.RGBA64 {
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// forEachRGBA64 invokes a function on each point (x, y) in a destination
+// rectangle r, passing it the color of the corresponding pixel of src, where
+// the source point sp corresponds to r.Min.  r must already be clipped to
+// src's bounds.  forEachRGBA64 reads the pixels of the most common standard
+// library image types directly, avoiding the memory allocation incurred by
+// calling At on each pixel.
+func forEachRGBA64(r image.Rectangle, src image.Image, sp image.Point, f func(x, y int, c color.RGBA64)) {
+	if r.Empty() {
+		return
+	}
+	dx, dy := sp.X-r.Min.X, sp.Y-r.Min.Y
+	switch src := src.(type) {
+	case *image.NRGBA:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			i := src.PixOffset(r.Min.X+dx, y+dy)
+			for x := r.Min.X; x < r.Max.X; x++ {
+				s := src.Pix[i : i+4 : i+4] // Small cap improves performance, see https://golang.org/issue/27857
+				f(x, y, rgba64(color.NRGBA{s[0], s[1], s[2], s[3]}.RGBA()))
+				i += 4
+			}
+		}
+	case *image.RGBA:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			i := src.PixOffset(r.Min.X+dx, y+dy)
+			for x := r.Min.X; x < r.Max.X; x++ {
+				s := src.Pix[i : i+4 : i+4] // Small cap improves performance, see https://golang.org/issue/27857
+				f(x, y, rgba64(color.RGBA{s[0], s[1], s[2], s[3]}.RGBA()))
+				i += 4
+			}
+		}
+	case *image.NRGBA64:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			i := src.PixOffset(r.Min.X+dx, y+dy)
+			for x := r.Min.X; x < r.Max.X; x++ {
+				s := src.Pix[i : i+8 : i+8] // Small cap improves performance, see https://golang.org/issue/27857
+				c := color.NRGBA64{
+					R: uint16(s[0])<<8 | uint16(s[1]),
+					G: uint16(s[2])<<8 | uint16(s[3]),
+					B: uint16(s[4])<<8 | uint16(s[5]),
+					A: uint16(s[6])<<8 | uint16(s[7]),
+				}
+				f(x, y, rgba64(c.RGBA()))
+				i += 8
+			}
+		}
+	case *image.Gray:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			i := src.PixOffset(r.Min.X+dx, y+dy)
+			for x := r.Min.X; x < r.Max.X; x++ {
+				f(x, y, rgba64(color.Gray{src.Pix[i]}.RGBA()))
+				i++
+			}
+		}
+	case *image.YCbCr:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				yi := src.YOffset(x+dx, y+dy)
+				ci := src.COffset(x+dx, y+dy)
+				c := color.YCbCr{src.Y[yi], src.Cb[ci], src.Cr[ci]}
+				f(x, y, rgba64(c.RGBA()))
+			}
+		}
+	case *image.Paletted:
+		// Convert each palette entry only once.  Out-of-range indexes
+		// produce transparent black.
+		pal := make([]color.RGBA64, 256)
+		for i, c := range src.Palette {
+			if i < len(pal) {
+				pal[i] = rgba64(c.RGBA())
+			}
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			i := src.PixOffset(r.Min.X+dx, y+dy)
+			for x := r.Min.X; x < r.Max.X; x++ {
+				f(x, y, pal[src.Pix[i]])
+				i++
+			}
+		}
+	case image.RGBA64Image:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				f(x, y, src.RGBA64At(x+dx, y+dy))
+			}
+		}
+	default:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				f(x, y, rgba64(src.At(x+dx, y+dy).RGBA()))
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// This file defines a suite of tests for bulk image operations.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// randomSources returns one randomly colored image of each type for which
+// AddImage and SetImage have a fast path, plus one of a type that requires a
+// slower path.
+func randomSources(r image.Rectangle) []image.Image {
+	rng := rand.New(rand.NewSource(1234))
+	nrgba := image.NewNRGBA(r)
+	rgba := image.NewRGBA(r)
+	nrgba64 := image.NewNRGBA64(r)
+	gray := image.NewGray(r)
+	ycbcr := image.NewYCbCr(r, image.YCbCrSubsampleRatio420)
+	paletted := image.NewPaletted(r, palette.Plan9)
+	cmyk := image.NewCMYK(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := color.NRGBA64{
+				R: uint16(rng.Intn(65536)),
+				G: uint16(rng.Intn(65536)),
+				B: uint16(rng.Intn(65536)),
+				A: uint16(rng.Intn(65536)),
+			}
+			nrgba.Set(x, y, c)
+			rgba.Set(x, y, c)
+			nrgba64.Set(x, y, c)
+			gray.Set(x, y, c)
+			paletted.Set(x, y, c)
+			cmyk.Set(x, y, c)
+			ycbcr.Y[ycbcr.YOffset(x, y)] = uint8(c.R >> 8)
+			ycbcr.Cb[ycbcr.COffset(x, y)] = uint8(c.G >> 8)
+			ycbcr.Cr[ycbcr.COffset(x, y)] = uint8(c.B >> 8)
+		}
+	}
+	return []image.Image{nrgba, rgba, nrgba64, gray, ycbcr, paletted, cmyk}
+}
+
+// TestNRGBAAddImage confirms that AddImage and SetImage produce the same
+// result as calling Add and Set on each pixel.
+func TestNRGBAAddImage(t *testing.T) {
+	srcRect := image.Rect(-5, -4, 20, 15)
+	dstRect := image.Rect(0, 0, 16, 12)
+	r := image.Rect(-3, 2, 12, 20)
+	sp := image.Pt(1, -6)
+	for _, src := range randomSources(srcRect) {
+		img1 := NewNRGBA(dstRect)
+		img2 := NewNRGBA(dstRect)
+		img1.SetImage(r, src, sp)
+		img1.AddImage(r, src, sp)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				sx, sy := x-r.Min.X+sp.X, y-r.Min.Y+sp.Y
+				if !(image.Point{sx, sy}.In(srcRect)) {
+					continue
+				}
+				img2.Set(x, y, src.At(sx, sy))
+				img2.Add(x, y, src.At(sx, sy))
+			}
+		}
+		for y := dstRect.Min.Y; y < dstRect.Max.Y; y++ {
+			for x := dstRect.Min.X; x < dstRect.Max.X; x++ {
+				c1, c2 := img1.NRGBAAt(x, y), img2.NRGBAAt(x, y)
+				if c1 != c2 {
+					t.Fatalf("%T: expected %v but saw %v at (%d, %d)", src, c2, c1, x, y)
+				}
+			}
+		}
+	}
+
+	// Adding an NRGBA to itself should double every sum.
+	img := NewNRGBA(dstRect)
+	img.AddImage(dstRect, randomSources(dstRect)[0], dstRect.Min)
+	exp := img.NRGBAAt(3, 4)
+	exp.Scale(2)
+	img.AddImage(dstRect, img, dstRect.Min)
+	if act := img.NRGBAAt(3, 4); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+}
+
+// TestLabAAddImage confirms that AddImage and SetImage produce the same
+// result as calling AddRGBA64 and SetRGBA64 on each pixel.
+func TestLabAAddImage(t *testing.T) {
+	rect := image.Rect(0, 0, 10, 10)
+	r := image.Rect(2, 3, 8, 9)
+	sp := image.Pt(0, 0)
+	for _, src := range randomSources(rect) {
+		img1 := NewLabA(rect)
+		img2 := NewLabA(rect)
+		img1.SetImage(r, src, sp)
+		img1.AddImage(r, src, sp)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				c := color.RGBA64Model.Convert(src.At(x-r.Min.X, y-r.Min.Y)).(color.RGBA64)
+				img2.SetRGBA64(x, y, c)
+				img2.AddRGBA64(x, y, c)
+			}
+		}
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				c1, c2 := img1.LabAAt(x, y), img2.LabAAt(x, y)
+				if c1 != c2 {
+					t.Fatalf("%T: expected %v but saw %v at (%d, %d)", src, c2, c1, x, y)
+				}
+			}
+		}
+	}
+}
+
+// TestSetImageOverlap copies overlapping regions of an image onto itself in
+// each direction and confirms that the result matches copying from an
+// unmodified copy of the image.
+func TestSetImageOverlap(t *testing.T) {
+	bnds := image.Rect(0, 0, 6, 5)
+	for _, sp := range []image.Point{{0, 0}, {2, 1}, {1, 0}, {0, 2}, {-1, 1}} {
+		r := image.Rect(1, 1, 5, 4)
+		fill := func(img AccumImage) {
+			for y := bnds.Min.Y; y < bnds.Max.Y; y++ {
+				for x := bnds.Min.X; x < bnds.Max.X; x++ {
+					img.Set(x, y, color.NRGBA{R: uint8(40 * x), G: uint8(50 * y), A: 255})
+				}
+			}
+		}
+
+		// Test NRGBA.
+		nrgba, orig := NewNRGBA(bnds), NewNRGBA(bnds)
+		fill(nrgba)
+		fill(orig)
+		nrgba.SetImage(r, nrgba, sp)
+		exp := NewNRGBA(bnds)
+		fill(exp)
+		exp.SetImage(r, orig, sp)
+		if !reflect.DeepEqual(nrgba.Pix, exp.Pix) {
+			t.Fatalf("NRGBA: overlapping copy from %v produced incorrect pixels", sp)
+		}
+
+		// Test LabA.
+		laba, origLab := NewLabA(bnds), NewLabA(bnds)
+		fill(laba)
+		fill(origLab)
+		laba.AddImage(r, laba, sp)
+		expLab := NewLabA(bnds)
+		fill(expLab)
+		expLab.AddImage(r, origLab, sp)
+		if !reflect.DeepEqual(laba.Pix, expLab.Pix) {
+			t.Fatalf("LabA: overlapping accumulation from %v produced incorrect pixels", sp)
+		}
+	}
+}
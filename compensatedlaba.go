@@ -0,0 +1,173 @@
+// This file defines the CompensatedLabA type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// A CompensatedLabA is an in-memory image whose At method returns
+// accumcolor.CompensatedLabA values.
+type CompensatedLabA struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.CompensatedLabA
+	// Stride is the Pix stride (in accumcolor.CompensatedLabA values)
+	// between vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewCompensatedLabA returns a new CompensatedLabA image with the given
+// bounds.
+func NewCompensatedLabA(r image.Rectangle) *CompensatedLabA {
+	return &CompensatedLabA{
+		Pix:    make([]accumcolor.CompensatedLabA, pixelBufferLength(1, r, "CompensatedLabA")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *CompensatedLabA) At(x, y int) color.Color {
+	return p.CompensatedLabAAt(x, y)
+}
+
+// CompensatedLabAAt returns the color of the pixel at (x, y) as an
+// accumcolor.CompensatedLabA.
+func (p *CompensatedLabA) CompensatedLabAAt(x, y int) accumcolor.CompensatedLabA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.CompensatedLabA{}
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// LabAAt returns the color of the pixel at (x, y) as an accumcolor.LabA, with
+// its compensation terms folded into its sums.
+func (p *CompensatedLabA) LabAAt(x, y int) accumcolor.LabA {
+	return p.CompensatedLabAAt(x, y).LabA()
+}
+
+// ColorfulAt returns the color of the pixel at (x, y) as a fully opaque
+// colorful.Color (from the go-colorful package).
+func (p *CompensatedLabA) ColorfulAt(x, y int) colorful.Color {
+	return p.CompensatedLabAAt(x, y).Colorful()
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *CompensatedLabA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *CompensatedLabA) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the CompensatedLabA's color model (always
+// accumcolor.CompensatedLabAModel).
+func (p *CompensatedLabA) ColorModel() color.Model {
+	return accumcolor.CompensatedLabAModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *CompensatedLabA) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
+			if clr.Tally == 0 || clr.Alpha != 255*clr.Tally {
+				return false
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *CompensatedLabA) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.CompensatedLabAAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *CompensatedLabA) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.CompensatedLabAModel.Convert(c).(accumcolor.CompensatedLabA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *CompensatedLabA) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.CompensatedLabAModel.Convert(c).(accumcolor.CompensatedLabA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SetCompensatedLabA sets the pixel at (x, y) to a given color of type
+// accumcolor.CompensatedLabA.
+func (p *CompensatedLabA) SetCompensatedLabA(x, y int, c accumcolor.CompensatedLabA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddCompensatedLabA accumulates a given color of type
+// accumcolor.CompensatedLabA to the pixel at (x, y).
+func (p *CompensatedLabA) AddCompensatedLabA(x, y int, c accumcolor.CompensatedLabA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *CompensatedLabA) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.CompensatedLabAModel.Convert(c).(accumcolor.CompensatedLabA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *CompensatedLabA) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.CompensatedLabAModel.Convert(c).(accumcolor.CompensatedLabA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *CompensatedLabA) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &CompensatedLabA{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &CompensatedLabA{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
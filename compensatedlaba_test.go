@@ -0,0 +1,42 @@
+// This file defines a suite of tests for accumimage.CompensatedLabA.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// TestCompensatedLabAAdd adds a large number of colors to an image and
+// confirms that the sums are accurate.
+func TestCompensatedLabAAdd(t *testing.T) {
+	img := NewCompensatedLabA(image.Rect(-2, -2, 2, 2))
+	clr := color.NRGBA{R: 30, G: 144, B: 255, A: 255}
+	one := accumcolor.LabAModel.Convert(clr).(accumcolor.LabA)
+	const n = 100000
+	for i := 0; i < n; i++ {
+		img.AddRGBA64(-1, 1, color.RGBA64{R: 0x1e1e, G: 0x9090, B: 0xffff, A: 0xffff})
+	}
+	act := img.LabAAt(-1, 1)
+	if act.Tally != n || act.Alpha != 255*n {
+		t.Fatalf("incorrect tally or alpha in %v", act)
+	}
+	if math.Abs(act.L-one.L*n) > 1e-9 ||
+		math.Abs(act.A-one.A*n) > 1e-9 ||
+		math.Abs(act.B-one.B*n) > 1e-9 {
+		t.Fatalf("expected %v times %d but saw %v", one, n, act)
+	}
+	if img.Opaque() {
+		t.Fatal("expected the image not to be opaque")
+	}
+	if !img.SubImage(image.Rect(-1, 1, 0, 2)).(*CompensatedLabA).Opaque() {
+		t.Fatal("expected the sub-image to be opaque")
+	}
+	if exp := one.Colorful(); img.ColorfulAt(-1, 1).DistanceLab(exp) > 1e-6 {
+		t.Fatalf("expected %v but saw %v", exp, img.ColorfulAt(-1, 1))
+	}
+}
@@ -4,9 +4,71 @@ accumulated and averaged.  It is based on the color types defined in
 accumimage/accumcolor.  The core data types that accumimage defines
 are AccumNRGBA and AccumLabA, which implement the image.Image
 interface as well as most of the standard set of methods provided by
-the image package's image types.  (AccumLabA lacks PixOffset.)  In
-addition, each Accum____.Set* method has a corresponding
-Accum____.Add* method, which adds color to a pixel rather than
-replacing the pixel's color with a given color.
+the image package's image types.  In addition, each Accum____.Set*
+method has a corresponding Accum____.Add* method, which adds color to
+a pixel rather than replacing the pixel's color with a given color.
+AddImage and SetImage apply Add and Set to an entire rectangle of
+pixels drawn from another image, reading the most common
+standard-library image types without per-pixel overhead.  AccumNRGBA
+and AccumLabA can also undo earlier accumulations, pixel by pixel with
+Sub* or image by image with Remove, which is useful for maintaining a
+sliding-window average.  Setting an AccumNRGBA's Saturate field makes
+it discard colors whose accumulation would overflow a pixel.
+
+Additional image types follow the same conventions.  NRGBA64 is a
+16-bit-per-channel counterpart of AccumNRGBA, and Gray and Gray16 are
+single-channel counterparts.  LinearNRGBA averages colors in linear
+light rather than in gamma-encoded sRGB.  RGBA64 weights each color by
+its alpha so that transparent pixels do not tint the result.  OkLabA
+is an Oklab counterpart of AccumLabA, and LChA, HSVA, and HSLA average
+hues circularly.  CompensatedLabA is a variant of AccumLabA that uses
+compensated summation to remain accurate over very long accumulations.
+WeightedNRGBA and WeightedLabA maintain floating-point tallies and
+provide an AddWeighted method for accumulating colors with fractional
+weights and AddAt and AddAtKernel methods for splatting colors at
+sub-pixel locations with bilinear or kernel-defined (e.g., Gaussian)
+weights.  Because their tallies are fractional, they also provide a
+Decay method that fades out earlier colors, which, interleaved with
+Add, maintains an exponential moving average.  NRGBAStats and
+LabAStats track per-pixel variance and can export a variance map.
+MinNRGBA and MaxNRGBA retain the per-channel minimum and maximum color
+accumulated at each pixel.  MedianNRGBA retains every sample
+accumulated at each pixel and produces per-pixel median and percentile
+images, which are robust to outliers.  SigmaClipNRGBA and
+SigmaClipLabA instead stack a sequence of frames with kappa-sigma
+clipping, either rejecting or winsorizing samples that lie too far
+from the mean.
+
+Image is a generic image type whose pixels can be of any accumulating
+color type, as in NewImage[accumcolor.OkLabA](r).  It provides the
+same methods as the types above, with AccumAt, SetAccum, and AddAccum
+standing in for the type-specific accessors, and works with any color
+type whose pointer implements accumcolor.Accumulator.
+
+Downscale shrinks an image to arbitrary bounds, averaging colors in
+either an AccumNRGBA or an AccumLabA.  Source pixels that straddle
+multiple target pixels contribute to each in proportion to the area of
+overlap.
+
+Resample more generally maps an image through a Transform, which may
+scale by non-integer factors in either direction and may offset the
+result, and splats each source pixel onto an AccumImage through a
+BoxKernel, TriangleKernel, LanczosKernel, or MitchellKernel, weighting
+it by the integral of the kernel over its footprint.  Destination
+pixels that no source pixel covers retain a zero tally.  WeightedNRGBA
+and WeightedLabA receive fractional weights directly.  Weighted wraps
+images with integer tallies so that they, too, can accumulate
+fractional weights, which it scales by WeightLevels.
+
+Every image type in this package is an AccumImage and hence a
+draw.Image, so draw.Draw and draw.DrawMask can draw onto it with
+draw.Over or draw.Src.  This package's own DrawMask function
+additionally supports an Accumulate operator, which adds source
+colors, weighted by an optional mask, instead of compositing them.
+Accumulating wraps an AccumImage so that code that can only call Set,
+such as the scalers in golang.org/x/image/draw, accumulates colors
+instead.  The accumimage/raster package additionally draws
+anti-aliased lines and curves and fills polygons and paths onto an
+AccumImage by accumulating colors weighted by coverage.
 */
 package accumimage
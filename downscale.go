@@ -0,0 +1,119 @@
+// This file defines a function for downscaling images by area averaging.
+
+package accumimage
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// A ColorSpace specifies the color space in which a function such as
+// Downscale averages colors.  The zero ColorSpace is deliberately invalid so
+// that an unset ColorSpace is not mistaken for a choice of color space.
+type ColorSpace int
+
+const (
+	// NRGBASpace averages colors as non-alpha-premultiplied RGBA and
+	// produces an NRGBA image.
+	NRGBASpace ColorSpace = iota + 1
+	// LabASpace averages colors as CIE L*a*b* + alpha and produces a LabA
+	// image.
+	LabASpace
+)
+
+// A span represents the portion of a source pixel that overlaps a single
+// destination pixel along one axis.
+type span struct {
+	Offset int    // Destination pixel offset from the minimum bound
+	Weight uint64 // Length of the overlap, in arbitrary units
+}
+
+// gcd returns the greatest common divisor of two positive integers.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// overlaps maps each of n source pixels along one axis onto m destination
+// pixels along the same axis.  It returns, for each source pixel, the
+// destination pixels it overlaps and the length of each overlap.  Lengths are
+// expressed in units for which a source pixel has length m/gcd(n, m) and a
+// destination pixel has length n/gcd(n, m), so every length is an integer.
+func overlaps(n, m int) [][]span {
+	g := gcd(n, m)
+	srcLen, dstLen := m/g, n/g
+	spans := make([][]span, n)
+	for i := range spans {
+		lo, hi := i*srcLen, (i+1)*srcLen
+		for j := lo / dstLen; j*dstLen < hi; j++ {
+			jlo, jhi := j*dstLen, (j+1)*dstLen
+			if jlo < lo {
+				jlo = lo
+			}
+			if jhi > hi {
+				jhi = hi
+			}
+			spans[i] = append(spans[i], span{Offset: j, Weight: uint64(jhi - jlo)})
+		}
+	}
+	return spans
+}
+
+// Downscale scales an image to fit given bounds by averaging, in a given
+// color space, all source pixels that map to each destination pixel.  The
+// scaling ratio need not be an integer.  A source pixel that straddles
+// multiple destination pixels contributes to each in proportion to the area
+// of overlap.  Downscale returns an *NRGBA if space is NRGBASpace and a *LabA
+// if space is LabASpace.  It panics if space is any other value, including the
+// zero ColorSpace.  Although intended for downscaling, Downscale can also
+// upscale, in which case each destination pixel blends the colors of the at
+// most four source pixels it overlaps.
+func Downscale(src image.Image, newBounds image.Rectangle, space ColorSpace) AccumImage {
+	// Prepare to accumulate colors in the requested color space.
+	var dst AccumImage
+	var add func(x, y int, c color.Color, w uint64)
+	switch space {
+	case LabASpace:
+		img := NewLabA(newBounds)
+		add = func(x, y int, c color.Color, w uint64) {
+			clr := c.(accumcolor.LabA)
+			clr.Scale(w)
+			img.AddLabA(x, y, clr)
+		}
+		dst = img
+	case NRGBASpace:
+		img := NewNRGBA(newBounds)
+		add = func(x, y int, c color.Color, w uint64) {
+			clr := c.(accumcolor.NRGBA)
+			clr.Scale(w)
+			img.AddNRGBA(x, y, clr)
+		}
+		dst = img
+	default:
+		panic(fmt.Sprintf("accumimage: Downscale given unknown ColorSpace %d", space))
+	}
+	sb := src.Bounds()
+	if sb.Empty() || newBounds.Empty() {
+		return dst
+	}
+
+	// Accumulate each source pixel into every destination pixel it
+	// overlaps.
+	model := dst.ColorModel()
+	xs := overlaps(sb.Dx(), newBounds.Dx())
+	ys := overlaps(sb.Dy(), newBounds.Dy())
+	forEachRGBA64(sb, src, sb.Min, func(x, y int, c color.RGBA64) {
+		clr := model.Convert(c)
+		for _, sy := range ys[y-sb.Min.Y] {
+			for _, sx := range xs[x-sb.Min.X] {
+				add(newBounds.Min.X+sx.Offset, newBounds.Min.Y+sy.Offset, clr, sx.Weight*sy.Weight)
+			}
+		}
+	})
+	return dst
+}
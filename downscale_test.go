@@ -0,0 +1,96 @@
+// This file defines a suite of tests for Downscale.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// TestOverlaps confirms that overlaps splits source pixels correctly.
+func TestOverlaps(t *testing.T) {
+	// Three source pixels map onto two destination pixels.  The middle
+	// source pixel is split evenly between them.
+	spans := overlaps(3, 2)
+	exp := [][]span{
+		{{0, 2}},
+		{{0, 1}, {1, 1}},
+		{{1, 2}},
+	}
+	for i, e := range exp {
+		if len(spans[i]) != len(e) {
+			t.Fatalf("expected %v but saw %v", exp, spans)
+		}
+		for j := range e {
+			if spans[i][j] != e[j] {
+				t.Fatalf("expected %v but saw %v", exp, spans)
+			}
+		}
+	}
+}
+
+// TestDownscaleNRGBA downscales an image by a non-integer ratio and confirms
+// that each pixel's color is the area-weighted average of the source pixels
+// it covers.
+func TestDownscaleNRGBA(t *testing.T) {
+	src := image.NewGray(image.Rect(10, 10, 13, 12))
+	vals := []uint8{0, 90, 180, 30, 120, 210}
+	copy(src.Pix, vals)
+	img := Downscale(src, image.Rect(0, 0, 2, 1), NRGBASpace).(*NRGBA)
+	for x, e := range []uint8{
+		(2*0 + 90 + 2*30 + 120) / 6,
+		(90 + 2*180 + 120 + 2*210) / 6,
+	} {
+		if c := img.ColorNRGBAAt(x, 0); c.R != e || c.G != e || c.B != e || c.A != 255 {
+			t.Fatalf("expected gray level %d but saw %v at (%d, 0)", e, c, x)
+		}
+	}
+	if img.NRGBAAt(0, 0).Tally != img.NRGBAAt(1, 0).Tally {
+		t.Fatal("expected all pixels to have the same tally")
+	}
+}
+
+// TestDownscaleLabA downscales a checkerboard by an integer ratio and
+// confirms that the result is uniform.
+func TestDownscaleLabA(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 8, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 8; x++ {
+			if (x+y)%2 == 0 {
+				src.Set(x, y, color.White)
+			} else {
+				src.Set(x, y, color.Black)
+			}
+		}
+	}
+	img := Downscale(src, image.Rect(0, 0, 4, 3), LabASpace).(*LabA)
+	white := accumcolor.LabAModel.Convert(color.White).(accumcolor.LabA)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			avg := img.LabAAt(x, y).Average()
+			if math.Abs(avg.L-white.L/2) > 1e-9 || avg.Alpha != 255 {
+				t.Fatalf("expected L = %v but saw %v at (%d, %d)",
+					white.L/2, avg, x, y)
+			}
+		}
+	}
+}
+
+// TestDownscaleInvalid confirms that Downscale rejects an invalid color
+// space.
+func TestDownscaleInvalid(t *testing.T) {
+	for _, space := range []ColorSpace{0, LabASpace + 1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected Downscale to panic on ColorSpace %d", space)
+				}
+			}()
+			Downscale(image.NewGray(image.Rect(0, 0, 2, 2)), image.Rect(0, 0, 1, 1), space)
+		}()
+	}
+}
@@ -0,0 +1,173 @@
+// This file provides support for drawing onto accumulating images with the
+// standard library's image/draw package.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// An AccumImage is an image whose pixels can accumulate color.  All of the
+// image types in this package are AccumImages.  AccumImages are also
+// draw.Images, so the standard library's draw.Draw and draw.DrawMask, as well
+// as third-party scalers that accept a draw.Image, can draw onto them with
+// draw.Over or draw.Src, which replace each pixel with a single composited
+// color.
+type AccumImage interface {
+	draw.Image
+	Add(x, y int, c color.Color)
+}
+
+// Ensure at compile time that every image type implements AccumImage.
+var (
+	_ AccumImage = (*NRGBA)(nil)
+	_ AccumImage = (*NRGBA64)(nil)
+	_ AccumImage = (*Gray)(nil)
+	_ AccumImage = (*Gray16)(nil)
+	_ AccumImage = (*LinearNRGBA)(nil)
+	_ AccumImage = (*RGBA64)(nil)
+	_ AccumImage = (*LabA)(nil)
+	_ AccumImage = (*CompensatedLabA)(nil)
+	_ AccumImage = (*OkLabA)(nil)
+	_ AccumImage = (*LChA)(nil)
+	_ AccumImage = (*HSVA)(nil)
+	_ AccumImage = (*HSLA)(nil)
+	_ AccumImage = (*WeightedNRGBA)(nil)
+	_ AccumImage = (*WeightedLabA)(nil)
+	_ AccumImage = (*NRGBAStats)(nil)
+	_ AccumImage = (*LabAStats)(nil)
+	_ AccumImage = (*MinNRGBA)(nil)
+	_ AccumImage = (*MaxNRGBA)(nil)
+	_ AccumImage = (*MedianNRGBA)(nil)
+	_ AccumImage = (*Image[accumcolor.NRGBA, *accumcolor.NRGBA])(nil)
+)
+
+// An Op is a Porter-Duff compositing operator extended with an operator that
+// accumulates colors.
+type Op int
+
+const (
+	// Over specifies ``(src in mask) over dst'', as does draw.Over.
+	Over Op = Op(draw.Over)
+	// Src specifies ``src in mask'', as does draw.Src.
+	Src Op = Op(draw.Src)
+	// Accumulate specifies that ``src in mask'' be accumulated onto
+	// dst.
+	Accumulate Op = Src + 1
+)
+
+// Draw implements the draw.Drawer interface by calling the DrawMask function
+// with this Op.
+func (op Op) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	DrawMask(dst, r, src, sp, nil, image.Point{}, op)
+}
+
+// DrawMask is like draw.DrawMask but additionally supports the Accumulate
+// operator.  Over and Src are passed to draw.DrawMask.  Accumulate adds the
+// color of each pixel of src to the corresponding pixel of dst.  If mask is
+// not nil, each color is accumulated with a weight equal to the
+// corresponding alpha value in mask, scaled to [0, 1], as by the
+// WeightedAccumImage that Weighted returns.  On an image with integer
+// tallies, a fully opaque mask value therefore yields a weight of
+// WeightLevels.  Pixels of dst whose mask alpha is zero are left untouched,
+// so their tallies do not change.  If Weighted does not support dst, colors
+// whose mask alpha is nonzero are accumulated with a weight of 1.  If dst is
+// not an AccumImage, Accumulate behaves like Over.
+func DrawMask(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point, mask image.Image, mp image.Point, op Op) {
+	acc, ok := dst.(AccumImage)
+	switch {
+	case op != Accumulate:
+		draw.DrawMask(dst, r, src, sp, mask, mp, draw.Op(op))
+		return
+	case !ok:
+		draw.DrawMask(dst, r, src, sp, mask, mp, draw.Over)
+		return
+	}
+
+	// Use AddImage if there is no mask and dst provides one.
+	type imageAdder interface {
+		AddImage(r image.Rectangle, src image.Image, sp image.Point)
+	}
+	if fast, ok := dst.(imageAdder); ok && mask == nil {
+		fast.AddImage(r, src, sp)
+		return
+	}
+
+	// Clip r to the bounds of all of the images involved.
+	orig := r.Min
+	r = r.Intersect(acc.Bounds())
+	r = r.Intersect(src.Bounds().Add(orig.Sub(sp)))
+	if mask != nil {
+		r = r.Intersect(mask.Bounds().Add(orig.Sub(mp)))
+	}
+	sp = sp.Add(r.Min.Sub(orig))
+	mp = mp.Add(r.Min.Sub(orig))
+
+	// Accumulate each source pixel, weighted by the mask.
+	if mask == nil {
+		forEachRGBA64(r, src, sp, func(x, y int, c color.RGBA64) {
+			acc.Add(x, y, c)
+		})
+		return
+	}
+	wacc := Weighted(acc)
+	weightAt := maskWeights(mask)
+	d := mp.Sub(r.Min)
+	forEachRGBA64(r, src, sp, func(x, y int, c color.RGBA64) {
+		w := weightAt(x+d.X, y+d.Y)
+		switch {
+		case w == 0.0:
+			return
+		case wacc == nil:
+			acc.Add(x, y, c)
+		default:
+			wacc.AddWeighted(x, y, c, w)
+		}
+	})
+}
+
+// maskWeights returns a function that maps a point in a mask to the mask's
+// alpha value at that point, expressed as a weight in [0, 1].
+func maskWeights(mask image.Image) func(x, y int) float64 {
+	switch mask := mask.(type) {
+	case *image.Alpha:
+		return func(x, y int) float64 {
+			return float64(mask.Pix[mask.PixOffset(x, y)]) / 0xff
+		}
+	case *image.Uniform:
+		_, _, _, a := mask.RGBA()
+		w := float64(a) / 0xffff
+		return func(x, y int) float64 {
+			return w
+		}
+	default:
+		return func(x, y int) float64 {
+			_, _, _, a := mask.At(x, y).RGBA()
+			return float64(a) / 0xffff
+		}
+	}
+}
+
+// accumulating wraps an AccumImage, replacing its Set method with its Add
+// method.
+type accumulating struct {
+	AccumImage
+}
+
+// Set accumulates a given color of any type to the pixel at (x, y).
+func (p accumulating) Set(x, y int, c color.Color) {
+	p.Add(x, y, c)
+}
+
+// Accumulating returns a draw.Image that adds colors to a given AccumImage
+// wherever it would otherwise set them.  This enables code that can draw only
+// with Set, such as third-party image scalers, to accumulate colors.  Such
+// code should draw with draw.Src.  draw.Over would additionally blend each
+// color with the pixel's current average before accumulating it.
+func Accumulating(img AccumImage) draw.Image {
+	return accumulating{img}
+}
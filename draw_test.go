@@ -0,0 +1,124 @@
+// This file defines a suite of tests for drawing onto accumulating images.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// TestDrawStandardOps confirms that the standard library's draw.Draw can draw
+// onto an accumulating image with draw.Src and draw.Over.
+func TestDrawStandardOps(t *testing.T) {
+	r := image.Rect(0, 0, 4, 4)
+	white := image.NewUniform(color.White)
+	red := image.NewUniform(color.NRGBA{R: 255, A: 128})
+	for _, dst := range []AccumImage{NewNRGBA(r), NewLabA(r), NewOkLabA(r)} {
+		std := image.NewNRGBA(r)
+		for _, d := range []draw.Image{dst, std} {
+			draw.Draw(d, r, white, image.Point{}, draw.Src)
+			draw.Draw(d, image.Rect(1, 1, 3, 3), red, image.Point{}, draw.Over)
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				c1 := color.NRGBAModel.Convert(dst.At(x, y)).(color.NRGBA)
+				c2 := std.NRGBAAt(x, y)
+				if absDiff(c1.R, c2.R) > 1 || absDiff(c1.G, c2.G) > 1 ||
+					absDiff(c1.B, c2.B) > 1 || c1.A != c2.A {
+					t.Fatalf("%T: expected %v but saw %v at (%d, %d)",
+						dst, c2, c1, x, y)
+				}
+			}
+		}
+	}
+}
+
+// absDiff returns the absolute difference of two uint8s.
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// TestDrawMaskAccumulate confirms that the Accumulate operator adds colors
+// and weights them by a mask.
+func TestDrawMaskAccumulate(t *testing.T) {
+	img := NewNRGBA(image.Rect(0, 0, 4, 1))
+	blue := image.NewUniform(color.NRGBA{B: 255, A: 255})
+	green := image.NewUniform(color.NRGBA{G: 255, A: 255})
+	Accumulate.Draw(img, img.Rect, blue, image.Point{})
+	mask := image.NewAlpha(image.Rect(10, 10, 13, 11))
+	mask.SetAlpha(11, 10, color.Alpha{A: 255})
+	mask.SetAlpha(12, 10, color.Alpha{A: 0x80})
+	DrawMask(img, img.Rect, green, image.Point{}, mask, image.Pt(10, 10), Accumulate)
+	exp := []accumcolor.NRGBA{
+		{B: 255, A: 255, Tally: 1},
+		{G: 255 * WeightLevels, B: 255, A: 255 * (WeightLevels + 1), Tally: WeightLevels + 1},
+		{G: 255 * 0x80, B: 255, A: 255 * (0x80 + 1), Tally: 0x80 + 1},
+		{B: 255, A: 255, Tally: 1},
+	}
+	for x, e := range exp {
+		if act := img.NRGBAAt(x, 0); act != e {
+			t.Fatalf("expected %v but saw %v at (%d, 0)", e, act, x)
+		}
+	}
+
+	// Other operators should behave as in the standard library.
+	Src.Draw(img, img.Rect, blue, image.Point{})
+	if act := img.NRGBAAt(1, 0); act != exp[0] {
+		t.Fatalf("expected %v but saw %v", exp[0], act)
+	}
+}
+
+// TestDrawMaskWeighted confirms that the Accumulate operator weights colors
+// by a mask's alpha value on an image with fractional tallies.
+func TestDrawMaskWeighted(t *testing.T) {
+	img := NewWeightedNRGBA(image.Rect(0, 0, 2, 2))
+	red := image.NewUniform(color.NRGBA{R: 200, A: 255})
+	alpha := image.NewAlpha(img.Rect)
+	for i := range alpha.Pix {
+		alpha.Pix[i] = 0x66
+	}
+	for _, m := range []image.Image{image.NewUniform(color.Alpha{A: 0x66}), alpha} {
+		DrawMask(img, img.Rect, red, image.Point{}, m, image.Point{}, Accumulate)
+	}
+	e := accumcolor.WeightedNRGBA{R: 200 * 0.8, A: 255 * 0.8, Tally: 0.8}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			c := img.WeightedNRGBAAt(x, y)
+			if math.Abs(c.R-e.R) > 1e-9 || math.Abs(c.A-e.A) > 1e-9 || math.Abs(c.Tally-e.Tally) > 1e-9 {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", e, c, x, y)
+			}
+		}
+	}
+}
+
+// TestAccumulating confirms that drawing onto an Accumulating image adds
+// colors.
+func TestAccumulating(t *testing.T) {
+	img := NewLabA(image.Rect(0, 0, 3, 3))
+	dst := Accumulating(img)
+	draw.Draw(dst, image.Rect(0, 0, 2, 2), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, image.Rect(1, 1, 3, 3), image.Black, image.Point{}, draw.Src)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			var exp uint64
+			if x < 2 && y < 2 {
+				exp++
+			}
+			if x >= 1 && y >= 1 {
+				exp++
+			}
+			if tally := img.LabAAt(x, y).Tally; tally != exp {
+				t.Fatalf("expected a tally of %d but saw %d at (%d, %d)",
+					exp, tally, x, y)
+			}
+		}
+	}
+}
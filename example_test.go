@@ -116,3 +116,29 @@ func ExampleNRGBA_Add() {
 		log.Fatal(err)
 	}
 }
+
+// Scale down an arbitrary image to 40% of its size in each of x and y,
+// averaging colors in the CIE L*a*b* color space.  Unlike the hand-written
+// loop in the package example, Downscale splits source pixels that straddle
+// multiple target pixels.
+func ExampleDownscale() {
+	// Read an image from a file.
+	if len(os.Args) != 2 {
+		return
+	}
+	img, err := readImage(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Downscale the image.
+	bnds := img.Bounds()
+	newBnds := image.Rect(0, 0, bnds.Dx()*2/5, bnds.Dy()*2/5)
+	newImg := accumimage.Downscale(img, newBnds, accumimage.LabASpace)
+
+	// Write the downscaled image to a file.
+	err = writeImage("image.png", newImg)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/spakin/accumimage/v2
 
-go 1.17
+go 1.18
 
 require github.com/lucasb-eyer/go-colorful v1.2.0
@@ -0,0 +1,364 @@
+// This file defines the Gray and Gray16 types and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// NOTE: Many of the functions and methods in this file were copied verbatim or
+// nearly verbatim from the Go standard library (image/image.go).
+
+// A Gray is an in-memory image whose At method returns accumcolor.Gray
+// values.
+type Gray struct {
+	// Pix holds the image's pixels, in Y, Tally order. The pixel at
+	// (x, y) starts at Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)*2].
+	Pix []uint64
+	// Stride is the Pix stride (in uint64s) between vertically adjacent
+	// pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewGray returns a new Gray image with the given bounds.
+func NewGray(r image.Rectangle) *Gray {
+	return &Gray{
+		Pix:    make([]uint64, pixelBufferLength(2, r, "Gray")),
+		Stride: 2 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *Gray) At(x, y int) color.Color {
+	return p.GrayAt(x, y)
+}
+
+// GrayAt returns the color of the pixel at (x, y) as an accumcolor.Gray.
+func (p *Gray) GrayAt(x, y int) accumcolor.Gray {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.Gray{}
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	return accumcolor.Gray{Y: s[0], Tally: s[1]}
+}
+
+// ColorGrayAt returns the color of the pixel at (x, y) as a color.Gray.
+func (p *Gray) ColorGrayAt(x, y int) color.Gray {
+	return p.GrayAt(x, y).Gray()
+}
+
+// PixOffset returns the index of the first element of Pix that corresponds to
+// the pixel at (x, y).
+func (p *Gray) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*2
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *Gray) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the Gray's color model (always accumcolor.GrayModel).
+func (p *Gray) ColorModel() color.Model {
+	return accumcolor.GrayModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.  A
+// Gray image is opaque unless it contains pixels with no color accumulated.
+func (p *Gray) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 1, p.Rect.Dx()*2
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for i := i0; i < i1; i += 2 {
+			if p.Pix[i] == 0 {
+				return false // No color at this position
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *Gray) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.GrayAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *Gray) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	c1 := accumcolor.GrayModel.Convert(c).(accumcolor.Gray)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = c1.Y
+	s[1] = c1.Tally
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *Gray) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	c1 := accumcolor.GrayModel.Convert(c).(accumcolor.Gray)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += c1.Y
+	s[1] += c1.Tally
+}
+
+// SetGray sets the pixel at (x, y) to a given color of type accumcolor.Gray.
+func (p *Gray) SetGray(x, y int, c accumcolor.Gray) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = c.Y
+	s[1] = c.Tally
+}
+
+// AddGray accumulates a given color of type accumcolor.Gray to the pixel at
+// (x, y).
+func (p *Gray) AddGray(x, y int, c accumcolor.Gray) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += c.Y
+	s[1] += c.Tally
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *Gray) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	// This formula is the same as in color.grayModel.
+	gray := (19595*uint32(c.R) + 38470*uint32(c.G) + 7471*uint32(c.B) + 1<<15) >> 24
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = uint64(gray)
+	s[1] = 1
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *Gray) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	// This formula is the same as in color.grayModel.
+	gray := (19595*uint32(c.R) + 38470*uint32(c.G) + 7471*uint32(c.B) + 1<<15) >> 24
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += uint64(gray)
+	s[1]++
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *Gray) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &Gray{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &Gray{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
+
+// A Gray16 is an in-memory image whose At method returns accumcolor.Gray16
+// values.
+type Gray16 struct {
+	// Pix holds the image's pixels, in Y, Tally order. The pixel at
+	// (x, y) starts at Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)*2].
+	Pix []uint64
+	// Stride is the Pix stride (in uint64s) between vertically adjacent
+	// pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewGray16 returns a new Gray16 image with the given bounds.
+func NewGray16(r image.Rectangle) *Gray16 {
+	return &Gray16{
+		Pix:    make([]uint64, pixelBufferLength(2, r, "Gray16")),
+		Stride: 2 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *Gray16) At(x, y int) color.Color {
+	return p.Gray16At(x, y)
+}
+
+// Gray16At returns the color of the pixel at (x, y) as an accumcolor.Gray16.
+func (p *Gray16) Gray16At(x, y int) accumcolor.Gray16 {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.Gray16{}
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	return accumcolor.Gray16{Y: s[0], Tally: s[1]}
+}
+
+// ColorGray16At returns the color of the pixel at (x, y) as a
+// color.Gray16.
+func (p *Gray16) ColorGray16At(x, y int) color.Gray16 {
+	return p.Gray16At(x, y).Gray16()
+}
+
+// PixOffset returns the index of the first element of Pix that corresponds to
+// the pixel at (x, y).
+func (p *Gray16) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*2
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *Gray16) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the Gray16's color model (always
+// accumcolor.Gray16Model).
+func (p *Gray16) ColorModel() color.Model {
+	return accumcolor.Gray16Model
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.  A
+// Gray16 image is opaque unless it contains pixels with no color accumulated.
+func (p *Gray16) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 1, p.Rect.Dx()*2
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for i := i0; i < i1; i += 2 {
+			if p.Pix[i] == 0 {
+				return false // No color at this position
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *Gray16) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.Gray16At(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *Gray16) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	c1 := accumcolor.Gray16Model.Convert(c).(accumcolor.Gray16)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = c1.Y
+	s[1] = c1.Tally
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *Gray16) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	c1 := accumcolor.Gray16Model.Convert(c).(accumcolor.Gray16)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += c1.Y
+	s[1] += c1.Tally
+}
+
+// SetGray16 sets the pixel at (x, y) to a given color of type
+// accumcolor.Gray16.
+func (p *Gray16) SetGray16(x, y int, c accumcolor.Gray16) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = c.Y
+	s[1] = c.Tally
+}
+
+// AddGray16 accumulates a given color of type accumcolor.Gray16 to the pixel at
+// (x, y).
+func (p *Gray16) AddGray16(x, y int, c accumcolor.Gray16) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += c.Y
+	s[1] += c.Tally
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *Gray16) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	// This formula is the same as in color.gray16Model.
+	gray := (19595*uint32(c.R) + 38470*uint32(c.G) + 7471*uint32(c.B) + 1<<15) >> 16
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = uint64(gray)
+	s[1] = 1
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *Gray16) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	// This formula is the same as in color.gray16Model.
+	gray := (19595*uint32(c.R) + 38470*uint32(c.G) + 7471*uint32(c.B) + 1<<15) >> 16
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+2 : i+2] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += uint64(gray)
+	s[1]++
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *Gray16) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &Gray16{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &Gray16{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,73 @@
+// This file defines a suite of tests for accumimage.Gray and
+// accumimage.Gray16.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestGrayAdd adds together different numbers of colors and checks that the
+// averages are as expected.
+func TestGrayAdd(t *testing.T) {
+	// Construct a column of colors.
+	const n = 100
+	img := NewGray(image.Rect(0, 0, 1, n))
+	if img.Opaque() {
+		t.Fatal("expected an empty image not to be opaque")
+	}
+
+	// Accumulate the most colors to the first pixel, less to the second,
+	// less to the third, and so forth.
+	for i := 0; i < n; i++ {
+		c := color.Gray{Y: uint8(i + 50)}
+		for j := 0; j <= i; j++ {
+			img.Add(0, j, c)
+		}
+	}
+	if !img.Opaque() {
+		t.Fatal("expected a fully accumulated image to be opaque")
+	}
+
+	// Confirm that each pixel contains the expected color.
+	for i := 0; i < n; i++ {
+		c := img.ColorGrayAt(0, i)
+		exp := color.Gray{Y: uint8((n+i-1)/2 + 50)}
+		if c != exp {
+			t.Fatalf("expected %v but saw %v", exp, c)
+		}
+	}
+}
+
+// TestGray16SubImage modifies values in a subimage and ensures these
+// modifications are reflected in the original image.
+func TestGray16SubImage(t *testing.T) {
+	img1 := NewGray16(image.Rect(3, 3, 13, 13))
+	c1 := color.Gray16{Y: 0x1010}
+	c2 := color.RGBA64{R: 0x3030, G: 0x3030, B: 0x3030, A: 0xffff}
+	for y := 3; y < 13; y++ {
+		for x := 3; x < 13; x++ {
+			img1.Set(x, y, c1)
+		}
+	}
+	img2 := img1.SubImage(image.Rect(5, 5, 105, 105)).(*Gray16)
+	for y := 5; y < 13; y++ {
+		for x := 5; x < 13; x++ {
+			img2.AddRGBA64(x, y, c2)
+		}
+	}
+	for y := 3; y < 13; y++ {
+		for x := 3; x < 13; x++ {
+			exp := c1
+			if x >= 5 && y >= 5 {
+				exp = color.Gray16{Y: 0x2020}
+			}
+			act := img1.ColorGray16At(x, y)
+			if act != exp {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", exp, act, x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// This file defines the HSLA type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// An HSLA is an in-memory image whose At method returns accumcolor.HSLA
+// values.
+type HSLA struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.HSLA
+	// Stride is the Pix stride (in accumcolor.HSLAs) between vertically
+	// adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewHSLA returns a new HSLA image with the given bounds.
+func NewHSLA(r image.Rectangle) *HSLA {
+	return &HSLA{
+		Pix:    make([]accumcolor.HSLA, pixelBufferLength(1, r, "HSLA")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *HSLA) At(x, y int) color.Color {
+	return p.HSLAAt(x, y)
+}
+
+// HSLAAt returns the color of the pixel at (x, y) as an accumcolor.HSLA.
+func (p *HSLA) HSLAAt(x, y int) accumcolor.HSLA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.HSLA{}
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// ColorfulAt returns the color of the pixel at (x, y) as a fully opaque
+// colorful.Color (from the go-colorful package).
+func (p *HSLA) ColorfulAt(x, y int) colorful.Color {
+	return p.HSLAAt(x, y).Colorful()
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *HSLA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *HSLA) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the HSLA's color model (always
+// accumcolor.HSLAModel).
+func (p *HSLA) ColorModel() color.Model {
+	return accumcolor.HSLAModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *HSLA) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
+			if clr.Tally == 0 || clr.Alpha != 255*clr.Tally {
+				return false
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *HSLA) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.HSLAAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *HSLA) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.HSLAModel.Convert(c).(accumcolor.HSLA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *HSLA) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.HSLAModel.Convert(c).(accumcolor.HSLA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SetHSLA sets the pixel at (x, y) to a given color of type
+// accumcolor.HSLA.
+func (p *HSLA) SetHSLA(x, y int, c accumcolor.HSLA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddHSLA accumulates a given color of type accumcolor.HSLA to the pixel
+// at (x, y).
+func (p *HSLA) AddHSLA(x, y int, c accumcolor.HSLA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *HSLA) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.HSLAModel.Convert(c).(accumcolor.HSLA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *HSLA) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.HSLAModel.Convert(c).(accumcolor.HSLA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *HSLA) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &HSLA{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &HSLA{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,165 @@
+// This file defines the HSVA type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// An HSVA is an in-memory image whose At method returns accumcolor.HSVA
+// values.
+type HSVA struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.HSVA
+	// Stride is the Pix stride (in accumcolor.HSVAs) between vertically
+	// adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewHSVA returns a new HSVA image with the given bounds.
+func NewHSVA(r image.Rectangle) *HSVA {
+	return &HSVA{
+		Pix:    make([]accumcolor.HSVA, pixelBufferLength(1, r, "HSVA")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *HSVA) At(x, y int) color.Color {
+	return p.HSVAAt(x, y)
+}
+
+// HSVAAt returns the color of the pixel at (x, y) as an accumcolor.HSVA.
+func (p *HSVA) HSVAAt(x, y int) accumcolor.HSVA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.HSVA{}
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// ColorfulAt returns the color of the pixel at (x, y) as a fully opaque
+// colorful.Color (from the go-colorful package).
+func (p *HSVA) ColorfulAt(x, y int) colorful.Color {
+	return p.HSVAAt(x, y).Colorful()
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *HSVA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *HSVA) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the HSVA's color model (always
+// accumcolor.HSVAModel).
+func (p *HSVA) ColorModel() color.Model {
+	return accumcolor.HSVAModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *HSVA) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
+			if clr.Tally == 0 || clr.Alpha != 255*clr.Tally {
+				return false
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *HSVA) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.HSVAAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *HSVA) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.HSVAModel.Convert(c).(accumcolor.HSVA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *HSVA) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.HSVAModel.Convert(c).(accumcolor.HSVA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SetHSVA sets the pixel at (x, y) to a given color of type
+// accumcolor.HSVA.
+func (p *HSVA) SetHSVA(x, y int, c accumcolor.HSVA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddHSVA accumulates a given color of type accumcolor.HSVA to the pixel
+// at (x, y).
+func (p *HSVA) AddHSVA(x, y int, c accumcolor.HSVA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *HSVA) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.HSVAModel.Convert(c).(accumcolor.HSVA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *HSVA) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.HSVAModel.Convert(c).(accumcolor.HSVA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *HSVA) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &HSVA{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &HSVA{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,45 @@
+// This file defines a suite of tests for accumimage.HSVA.
+
+package accumimage
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// TestHSVAAdd accumulates colors on either side of 0° in a subimage and
+// confirms that the original image reports their circular-mean hue.
+func TestHSVAAdd(t *testing.T) {
+	img := NewHSVA(image.Rect(-5, -5, 5, 5))
+	sub := img.SubImage(image.Rect(0, 0, 5, 5)).(*HSVA)
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			sub.Add(x, y, colorful.Hsv(340.0, 0.8, 0.6))
+			sub.Add(x, y, colorful.Hsv(20.0, 0.8, 0.6))
+		}
+	}
+	for y := -5; y < 5; y++ {
+		for x := -5; x < 5; x++ {
+			c := img.HSVAAt(x, y)
+			if x < 0 || y < 0 {
+				if c.Tally != 0 {
+					t.Fatalf("expected no color at (%d, %d) but saw %v", x, y, c)
+				}
+				continue
+			}
+			if c.Tally != 2 {
+				t.Fatalf("incorrect tally at (%d, %d)", x, y)
+			}
+			h := c.Hue()
+			if h > 180.0 {
+				h -= 360.0
+			}
+			if math.Abs(h) > 1e-2 {
+				t.Fatalf("expected hue 0 but saw %v at (%d, %d)", c.Hue(), x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// This file defines the generic Image type and associated methods.
+
+package accumimage
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// An AccumulatorPtr is a pointer to an accumulating color of type C.  It
+// enables an Image to modify its pixels in place.
+type AccumulatorPtr[C any] interface {
+	*C
+	accumcolor.Accumulator
+}
+
+// An Image is an in-memory image whose pixels are accumulating colors of type
+// C, such as accumcolor.NRGBA or accumcolor.OkLabA.  P is always *C and
+// normally inferred.  An Image behaves like the package's other image types
+// but works with any accumulating color type, including those defined outside
+// of accumcolor.
+type Image[C color.Color, P AccumulatorPtr[C]] struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []C
+	// Stride is the Pix stride (in pixels) between vertically adjacent
+	// pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewImage returns a new Image with the given bounds and pixel type, as in
+// NewImage[accumcolor.LabA](r).
+func NewImage[C color.Color, P AccumulatorPtr[C]](r image.Rectangle) *Image[C, P] {
+	return &Image[C, P]{
+		Pix:    make([]C, pixelBufferLength(1, r, "Image")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// convert converts a color of any type to a color of type C by accumulating
+// it onto a zero C.
+func (p *Image[C, P]) convert(c color.Color) C {
+	var clr C
+	P(&clr).Add(c)
+	return clr
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *Image[C, P]) At(x, y int) color.Color {
+	return p.AccumAt(x, y)
+}
+
+// AccumAt returns the color of the pixel at (x, y) as a C.
+func (p *Image[C, P]) AccumAt(x, y int) C {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		var zero C
+		return zero
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *Image[C, P]) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *Image[C, P]) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the Image's color model, which converts colors to type
+// C.
+func (p *Image[C, P]) ColorModel() color.Model {
+	return color.ModelFunc(func(c color.Color) color.Color {
+		if clr, ok := c.(C); ok {
+			return clr
+		}
+		return p.convert(c)
+	})
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *Image[C, P]) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for i := i0; i < i1; i++ {
+			clr := P(&p.Pix[i])
+			if clr.Weight() == 0.0 {
+				return false // No color at this position
+			}
+			if _, _, _, a := clr.AverageColor().RGBA(); a != 0xffff {
+				return false // Not fully opaque
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *Image[C, P]) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.AccumAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *Image[C, P]) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = p.convert(c)
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *Image[C, P]) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	P(&p.Pix[p.PixOffset(x, y)]).Add(c)
+}
+
+// AddWeighted accumulates a given color of any type to the pixel at (x, y)
+// with a given nonnegative weight.  It panics if C cannot represent the
+// weight, as when C maintains an integer tally and w is not an integer.
+// Weighted instead scales weights by WeightLevels for such an Image.
+func (p *Image[C, P]) AddWeighted(x, y int, c color.Color, w float64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := p.convert(c)
+	if err := P(&clr).ScaleWeight(w); err != nil {
+		panic(fmt.Sprintf("accumimage: cannot accumulate a %T with weight %v", clr, w))
+	}
+	P(&p.Pix[p.PixOffset(x, y)]).Add(clr)
+}
+
+// weighted implements Weighted for an Image.  It returns p itself if C can
+// represent fractional weights and wraps p otherwise.
+func (p *Image[C, P]) weighted() WeightedAccumImage {
+	var zero C
+	if P(&zero).ScaleWeight(0.5) == nil {
+		return p
+	}
+	return integerWeighted{AccumImage: p, add: p.AddWeighted}
+}
+
+// SetAccum sets the pixel at (x, y) to a given color of type C.
+func (p *Image[C, P]) SetAccum(x, y int, c C) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddAccum accumulates a given color of type C to the pixel at (x, y).
+func (p *Image[C, P]) AddAccum(x, y int, c C) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	P(&p.Pix[p.PixOffset(x, y)]).Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *Image[C, P]) SetRGBA64(x, y int, c color.RGBA64) {
+	p.Set(x, y, c)
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *Image[C, P]) AddRGBA64(x, y int, c color.RGBA64) {
+	p.Add(x, y, c)
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *Image[C, P]) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &Image[C, P]{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &Image[C, P]{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,109 @@
+// This file defines a suite of tests for accumimage.Image.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// TestImageNRGBA confirms that an Image of accumcolor.NRGBA values behaves
+// the same as an NRGBA.
+func TestImageNRGBA(t *testing.T) {
+	r := image.Rect(-3, -2, 5, 4)
+	img1 := NewNRGBA(r)
+	img2 := NewImage[accumcolor.NRGBA](r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c1 := color.NRGBA{R: uint8(x * 20), G: uint8(y * 30), B: 128, A: 255}
+			c2 := color.RGBA64{R: 0x8000, G: 0x4000, B: 0x2000, A: 0xc000}
+			img1.Set(x, y, c1)
+			img2.Set(x, y, c1)
+			img1.AddRGBA64(x, y, c2)
+			img2.AddRGBA64(x, y, c2)
+			img1.AddNRGBA(x, y, img1.NRGBAAt(x, y))
+			img2.AddAccum(x, y, img2.AccumAt(x, y))
+		}
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if c1, c2 := img1.NRGBAAt(x, y), img2.AccumAt(x, y); c1 != c2 {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", c1, c2, x, y)
+			}
+			if c1, c2 := img1.RGBA64At(x, y), img2.RGBA64At(x, y); c1 != c2 {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", c1, c2, x, y)
+			}
+		}
+	}
+	if img2.Opaque() {
+		t.Fatal("expected the image not to be opaque")
+	}
+}
+
+// TestImageSubImage confirms that an Image's sub-images share pixels with
+// the original image.
+func TestImageSubImage(t *testing.T) {
+	img := NewImage[accumcolor.OkLabA](image.Rect(0, 0, 4, 4))
+	sub := img.SubImage(image.Rect(1, 1, 3, 3)).(*Image[accumcolor.OkLabA, *accumcolor.OkLabA])
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			sub.Add(x, y, color.White)
+			sub.Add(x, y, color.Black)
+		}
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			tally := img.AccumAt(x, y).Tally
+			switch {
+			case x >= 1 && x < 3 && y >= 1 && y < 3:
+				if tally != 2 {
+					t.Fatalf("expected a tally of 2 at (%d, %d) but saw %d", x, y, tally)
+				}
+			case tally != 0:
+				t.Fatalf("expected a tally of 0 at (%d, %d) but saw %d", x, y, tally)
+			}
+		}
+	}
+	if img.Opaque() {
+		t.Fatal("expected the image not to be opaque")
+	}
+	if !sub.Opaque() {
+		t.Fatal("expected the sub-image to be opaque")
+	}
+	exp := accumcolor.OkLabAModel.Convert(color.Black).(accumcolor.OkLabA)
+	exp.Add(color.White)
+	if act := img.ColorModel().Convert(sub.At(2, 2)); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+}
+
+// TestImageAddWeighted confirms that an Image accumulates colors with
+// weights its color type can represent, panics on other weights, and is
+// wrapped by Weighted only if its color type has an integer tally.
+func TestImageAddWeighted(t *testing.T) {
+	r := image.Rect(0, 0, 2, 2)
+	wimg := NewImage[accumcolor.WeightedNRGBA](r)
+	wimg.AddWeighted(1, 1, color.NRGBA{R: 100, A: 255}, 0.25)
+	if c := wimg.AccumAt(1, 1); c != (accumcolor.WeightedNRGBA{R: 25, A: 63.75, Tally: 0.25}) {
+		t.Fatalf("expected a tally of 0.25 but saw %v", c)
+	}
+	if Weighted(wimg) != WeightedAccumImage(wimg) {
+		t.Fatal("expected Weighted to return an Image of WeightedNRGBA unmodified")
+	}
+
+	nimg := NewImage[accumcolor.NRGBA](r)
+	nimg.AddWeighted(1, 1, color.NRGBA{R: 100, A: 255}, 3.0)
+	Weighted(nimg).AddWeighted(1, 1, color.NRGBA{R: 100, A: 255}, 0.5)
+	if c := nimg.AccumAt(1, 1); c != (accumcolor.NRGBA{R: 100 * 131, A: 255 * 131, Tally: 131}) {
+		t.Fatalf("expected a tally of 131 but saw %v", c)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected AddWeighted to panic on a fractional weight")
+		}
+	}()
+	nimg.AddWeighted(1, 1, color.NRGBA{R: 100, A: 255}, 0.5)
+}
@@ -3,6 +3,7 @@
 package accumimage
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 
@@ -12,23 +13,22 @@ import (
 
 // A LabA is an in-memory image whose At method returns accumcolor.LabA values.
 type LabA struct {
-	// Pix holds the image's pixels.  Pix[0][0] corresponds to (Rect.Min.X,
-	// Rect.Min.Y).
-	Pix [][]accumcolor.LabA
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.LabA
+	// Stride is the Pix stride (in accumcolor.LabA values) between
+	// vertically adjacent pixels.
+	Stride int
 	// Rect is the image's bounds.
 	Rect image.Rectangle
 }
 
 // NewLabA returns a new LabA image with the given bounds.
 func NewLabA(r image.Rectangle) *LabA {
-	wd, ht := r.Dx(), r.Dy()
-	pixels := make([][]accumcolor.LabA, ht)
-	for r := range pixels {
-		pixels[r] = make([]accumcolor.LabA, wd)
-	}
 	return &LabA{
-		Pix:  pixels,
-		Rect: r,
+		Pix:    make([]accumcolor.LabA, pixelBufferLength(1, r, "LabA")),
+		Stride: r.Dx(),
+		Rect:   r,
 	}
 }
 
@@ -43,7 +43,7 @@ func (p *LabA) LabAAt(x, y int) accumcolor.LabA {
 	if !(image.Point{x, y}.In(p.Rect)) {
 		return accumcolor.LabA{}
 	}
-	return p.Pix[y-p.Rect.Min.Y][x-p.Rect.Min.X]
+	return p.Pix[p.PixOffset(x, y)]
 }
 
 // ColorfulAt returns the color of the pixel at (x, y) as a fully opaque
@@ -52,6 +52,12 @@ func (p *LabA) ColorfulAt(x, y int) colorful.Color {
 	return p.LabAAt(x, y).Colorful()
 }
 
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *LabA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
 // Bounds returns the domain for which At can return non-zero color.
 func (p *LabA) Bounds() image.Rectangle { return p.Rect }
 
@@ -66,12 +72,15 @@ func (p *LabA) Opaque() bool {
 	if p.Rect.Empty() {
 		return true
 	}
-	for _, row := range p.Pix {
-		for _, clr := range row {
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
 			if clr.Alpha != 255*clr.Tally {
 				return false
 			}
 		}
+		i0 += p.Stride
+		i1 += p.Stride
 	}
 	return true
 }
@@ -88,7 +97,7 @@ func (p *LabA) Set(x, y int, c color.Color) {
 		return
 	}
 	clr := accumcolor.LabAModel.Convert(c).(accumcolor.LabA)
-	p.Pix[y-p.Rect.Min.Y][x-p.Rect.Min.X] = clr
+	p.Pix[p.PixOffset(x, y)] = clr
 }
 
 // Add accumulates a given color of any type to the pixel at (x, y).
@@ -97,7 +106,7 @@ func (p *LabA) Add(x, y int, c color.Color) {
 		return
 	}
 	clr := accumcolor.LabAModel.Convert(c).(accumcolor.LabA)
-	p.Pix[y-p.Rect.Min.Y][x-p.Rect.Min.X].Add(clr)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
 }
 
 // SetLabA sets the pixel at (x, y) to a given color of type
@@ -106,7 +115,7 @@ func (p *LabA) SetLabA(x, y int, c accumcolor.LabA) {
 	if !(image.Point{x, y}.In(p.Rect)) {
 		return
 	}
-	p.Pix[y-p.Rect.Min.Y][x-p.Rect.Min.X] = c
+	p.Pix[p.PixOffset(x, y)] = c
 }
 
 // AddLabA accumulates a given color of type accumcolor.LabA to the
@@ -115,7 +124,54 @@ func (p *LabA) AddLabA(x, y int, c accumcolor.LabA) {
 	if !(image.Point{x, y}.In(p.Rect)) {
 		return
 	}
-	p.Pix[y-p.Rect.Min.Y][x-p.Rect.Min.X].Add(c)
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// Sub removes a given color of any type, previously accumulated with Add, from
+// the pixel at (x, y).  It returns an error and leaves the pixel unmodified if
+// the color cannot be removed (see accumcolor.LabA.Sub).
+func (p *LabA) Sub(x, y int, c color.Color) error {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return nil
+	}
+	return p.Pix[p.PixOffset(x, y)].Sub(c)
+}
+
+// SubLabA removes a given color of type accumcolor.LabA, previously
+// accumulated with AddLabA, from the pixel at (x, y).  It returns an error and
+// leaves the pixel unmodified if the color cannot be removed (see
+// accumcolor.LabA.Sub).
+func (p *LabA) SubLabA(x, y int, c accumcolor.LabA) error {
+	return p.Sub(x, y, c)
+}
+
+// Remove subtracts each pixel of src from the corresponding pixel of p,
+// undoing an earlier accumulation of the same image.  Pixels of src that lie
+// outside p's bounds are ignored.  If any pixel cannot be removed, Remove
+// returns an error wrapping the one returned by accumcolor.LabA.Sub and leaves
+// p unmodified.
+func (p *LabA) Remove(src image.Image) error {
+	// Compute all differences before modifying any pixels.
+	r := p.Rect.Intersect(src.Bounds())
+	diffs := make([]accumcolor.LabA, 0, r.Dx()*r.Dy())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			clr := p.LabAAt(x, y)
+			if err := clr.Sub(src.At(x, y)); err != nil {
+				return fmt.Errorf("accumimage: cannot remove color at (%d, %d): %w", x, y, err)
+			}
+			diffs = append(diffs, clr)
+		}
+	}
+
+	// Store the differences.
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			p.SetLabA(x, y, diffs[0])
+			diffs = diffs[1:]
+		}
+	}
+	return nil
 }
 
 // SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
@@ -124,7 +180,7 @@ func (p *LabA) SetRGBA64(x, y int, c color.RGBA64) {
 		return
 	}
 	if c.A == 0 {
-		p.Pix[y-p.Rect.Min.Y][x-p.Rect.Min.X] = accumcolor.LabA{Tally: 1}
+		p.Pix[p.PixOffset(x, y)] = accumcolor.LabA{Tally: 1}
 		return
 	}
 	alpha := float64(c.A)
@@ -134,7 +190,7 @@ func (p *LabA) SetRGBA64(x, y int, c color.RGBA64) {
 		B: float64(c.B) / alpha,
 	}
 	L, a, b := clr.Lab()
-	p.Pix[y-p.Rect.Min.Y][x-p.Rect.Min.X] = accumcolor.LabA{
+	p.Pix[p.PixOffset(x, y)] = accumcolor.LabA{
 		L:     L,
 		A:     a,
 		B:     b,
@@ -149,7 +205,7 @@ func (p *LabA) AddRGBA64(x, y int, c color.RGBA64) {
 		return
 	}
 	if c.A == 0 {
-		p.Pix[y-p.Rect.Min.Y][x-p.Rect.Min.X].Add(accumcolor.LabA{Tally: 1})
+		p.Pix[p.PixOffset(x, y)].Add(accumcolor.LabA{Tally: 1})
 		return
 	}
 	alpha := float64(c.A)
@@ -159,7 +215,7 @@ func (p *LabA) AddRGBA64(x, y int, c color.RGBA64) {
 		B: float64(c.B) / alpha,
 	}
 	L, a, b := clr.Lab()
-	p.Pix[y-p.Rect.Min.Y][x-p.Rect.Min.X].Add(accumcolor.LabA{
+	p.Pix[p.PixOffset(x, y)].Add(accumcolor.LabA{
 		L:     L,
 		A:     a,
 		B:     b,
@@ -168,26 +224,59 @@ func (p *LabA) AddRGBA64(x, y int, c color.RGBA64) {
 	})
 }
 
+// SetImage sets each pixel in r to the color of the corresponding pixel of
+// src, where sp in src corresponds to r.Min, as in the standard library's
+// draw.Draw.  r is clipped to the bounds of both p and src.  If src is itself
+// a LabA, accumulated colors are copied exactly.  SetImage reads the pixels
+// of the most common standard-library image types directly, which is much
+// faster than calling Set on each pixel.
+func (p *LabA) SetImage(r image.Rectangle, src image.Image, sp image.Point) {
+	r, sp = clipImage(p.Rect, r, src, sp)
+	if src, ok := src.(*LabA); ok {
+		d := sp.Sub(r.Min)
+		back := processBackward(p, src, r, sp)
+		forEachPoint(r, back, func(x, y int) {
+			p.SetLabA(x, y, src.LabAAt(x+d.X, y+d.Y))
+		})
+		return
+	}
+	forEachRGBA64(r, src, sp, p.SetRGBA64)
+}
+
+// AddImage accumulates the color of each pixel of src to the corresponding
+// pixel in r, where sp in src corresponds to r.Min, as in the standard
+// library's draw.Draw.  r is clipped to the bounds of both p and src.  If src
+// is itself a LabA, accumulated colors are added exactly.  Otherwise, AddImage
+// reads the pixels of the most common standard-library image types directly,
+// which is much faster than calling Add on each pixel.
+func (p *LabA) AddImage(r image.Rectangle, src image.Image, sp image.Point) {
+	r, sp = clipImage(p.Rect, r, src, sp)
+	if src, ok := src.(*LabA); ok {
+		d := sp.Sub(r.Min)
+		back := processBackward(p, src, r, sp)
+		forEachPoint(r, back, func(x, y int) {
+			p.AddLabA(x, y, src.LabAAt(x+d.X, y+d.Y))
+		})
+		return
+	}
+	forEachRGBA64(r, src, sp, p.AddRGBA64)
+}
+
 // SubImage returns an image representing the portion of the image p visible
 // through r. The returned value shares pixels with the original image.
 func (p *LabA) SubImage(r image.Rectangle) image.Image {
 	r = r.Intersect(p.Rect)
 	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
-	// be inside either r1 or r2.  We explicitly check for this situation.
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
 	if r.Empty() {
 		return &LabA{}
 	}
-
-	// Create new rows, each containing a subset of the original columns.
-	wd, ht := r.Dx(), r.Dy()
-	xOfs := r.Min.X - p.Rect.Min.X
-	yOfs := r.Min.Y - p.Rect.Min.Y
-	pixels := make([][]accumcolor.LabA, ht)
-	for r := range pixels {
-		pixels[r] = p.Pix[yOfs+r][xOfs : xOfs+wd]
-	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
 	return &LabA{
-		Pix:  pixels,
-		Rect: r,
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
 	}
 }
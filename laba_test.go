@@ -3,7 +3,9 @@
 package accumimage
 
 import (
+	"errors"
 	"image"
+	"image/color"
 	"math"
 	"testing"
 
@@ -110,3 +112,68 @@ func TestLabASubImage(t *testing.T) {
 		}
 	}
 }
+
+// TestLabARemove confirms that removing an image undoes adding it.
+func TestLabARemove(t *testing.T) {
+	r := image.Rect(0, 0, 3, 3)
+	src := image.NewNRGBA(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 100), G: uint8(y * 100), B: 50, A: 255})
+		}
+	}
+	img := NewLabA(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Add(x, y, color.White)
+			img.Add(x, y, src.At(x, y))
+		}
+	}
+	if err := img.Remove(src); err != nil {
+		t.Fatal(err)
+	}
+	exp := accumcolor.LabAModel.Convert(color.White).(accumcolor.LabA)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			act := img.LabAAt(x, y)
+			if act.Tally != 1 || math.Abs(act.L-exp.L) > 1e-9 {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", exp, act, x, y)
+			}
+		}
+	}
+
+	// A failed removal should leave the image unmodified.
+	if err := img.Remove(src); !errors.Is(err, accumcolor.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid but saw %v", err)
+	}
+	if act := img.LabAAt(2, 2); act.Tally != 1 {
+		t.Fatalf("expected a tally of 1 but saw %d", act.Tally)
+	}
+}
+
+// TestLabAPixOffset confirms that PixOffset locates pixels in both an image
+// and its sub-images.
+func TestLabAPixOffset(t *testing.T) {
+	img := NewLabA(image.Rect(-2, -1, 6, 5))
+	sub := img.SubImage(image.Rect(1, 1, 4, 3)).(*LabA)
+	for y := -1; y < 5; y++ {
+		for x := -2; x < 6; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 30), G: uint8(y * 40), A: 255})
+		}
+	}
+	for y := 1; y < 3; y++ {
+		for x := 1; x < 4; x++ {
+			exp := img.Pix[img.PixOffset(x, y)]
+			if act := sub.Pix[sub.PixOffset(x, y)]; act != exp {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", exp, act, x, y)
+			}
+			if act := sub.LabAAt(x, y); act != exp {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", exp, act, x, y)
+			}
+		}
+	}
+	if len(img.Pix) != 8*6 || img.Stride != 8 || sub.Stride != 8 {
+		t.Fatalf("unexpected layout: len(Pix) = %d, Stride = %d",
+			len(img.Pix), img.Stride)
+	}
+}
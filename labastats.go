@@ -0,0 +1,196 @@
+// This file defines the LabAStats type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// A LabAStats is an in-memory image whose At method returns
+// accumcolor.LabAStats values.  In addition to each pixel's mean color, a
+// LabAStats tracks each pixel's variance, which indicates where an average
+// has and has not yet converged.
+type LabAStats struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.LabAStats
+	// Stride is the Pix stride (in accumcolor.LabAStats values) between
+	// vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewLabAStats returns a new LabAStats image with the given bounds.
+func NewLabAStats(r image.Rectangle) *LabAStats {
+	return &LabAStats{
+		Pix:    make([]accumcolor.LabAStats, pixelBufferLength(1, r, "LabAStats")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *LabAStats) At(x, y int) color.Color {
+	return p.LabAStatsAt(x, y)
+}
+
+// LabAStatsAt returns the color of the pixel at (x, y) as an
+// accumcolor.LabAStats.
+func (p *LabAStats) LabAStatsAt(x, y int) accumcolor.LabAStats {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.LabAStats{}
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// ColorfulAt returns the color of the pixel at (x, y) as a fully opaque
+// colorful.Color (from the go-colorful package).
+func (p *LabAStats) ColorfulAt(x, y int) colorful.Color {
+	return p.LabAStatsAt(x, y).Colorful()
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *LabAStats) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *LabAStats) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the LabAStats's color model (always
+// accumcolor.LabAStatsModel).
+func (p *LabAStats) ColorModel() color.Model {
+	return accumcolor.LabAStatsModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *LabAStats) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
+			if clr.Tally == 0 || clr.Alpha.Mean != 255.0 {
+				return false
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *LabAStats) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.LabAStatsAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *LabAStats) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LabAStatsModel.Convert(c).(accumcolor.LabAStats)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *LabAStats) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LabAStatsModel.Convert(c).(accumcolor.LabAStats)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SetLabAStats sets the pixel at (x, y) to a given color of type
+// accumcolor.LabAStats.
+func (p *LabAStats) SetLabAStats(x, y int, c accumcolor.LabAStats) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddLabAStats accumulates a given color of type accumcolor.LabAStats to the
+// pixel at (x, y).
+func (p *LabAStats) AddLabAStats(x, y int, c accumcolor.LabAStats) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *LabAStats) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LabAStatsModel.Convert(c).(accumcolor.LabAStats)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *LabAStats) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LabAStatsModel.Convert(c).(accumcolor.LabAStats)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// VarianceImage returns a variance map of the image.  Each pixel's red, green,
+// and blue channels represent the population variance of the L*, a*, and b*
+// channels of p, respectively, each scaled so that 0xffff corresponds to the
+// variance of an equal number of values at either end of the channel's range,
+// the largest population variance possible.  Pixels that have accumulated no
+// color are transparent in the variance map; all other pixels are opaque.
+func (p *LabAStats) VarianceImage() *image.NRGBA64 {
+	const maxVarL = 0.5 * 0.5
+	const maxVarAB = 1.0 * 1.0
+	img := image.NewNRGBA64(p.Rect)
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+			c := p.Pix[p.PixOffset(x, y)]
+			if c.Tally == 0 {
+				continue
+			}
+			L, a, b, _ := c.Variance()
+			img.SetNRGBA64(x, y, color.NRGBA64{
+				R: varianceToUint16(populationVariance(L, c.Tally), maxVarL),
+				G: varianceToUint16(populationVariance(a, c.Tally), maxVarAB),
+				B: varianceToUint16(populationVariance(b, c.Tally), maxVarAB),
+				A: 0xffff,
+			})
+		}
+	}
+	return img
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *LabAStats) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &LabAStats{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &LabAStats{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,49 @@
+// This file defines a suite of tests for accumimage.LabAStats.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// TestLabAStatsVarianceImage accumulates colors into a subimage and confirms
+// that the variance map of the original image reflects them.
+func TestLabAStatsVarianceImage(t *testing.T) {
+	img := NewLabAStats(image.Rect(0, 0, 4, 4))
+	sub := img.SubImage(image.Rect(1, 1, 3, 3)).(*LabAStats)
+	for y := 1; y < 3; y++ {
+		for x := 1; x < 3; x++ {
+			sub.AddLabAStats(x, y, accumcolor.LabAStats{
+				L:     accumcolor.Moments{Mean: 0.25},
+				Alpha: accumcolor.Moments{Mean: 255.0},
+				Tally: 1,
+			})
+			sub.AddLabAStats(x, y, accumcolor.LabAStats{
+				L:     accumcolor.Moments{Mean: 0.75},
+				Alpha: accumcolor.Moments{Mean: 255.0},
+				Tally: 1,
+			})
+		}
+	}
+	if img.Opaque() || !sub.Opaque() {
+		t.Fatal("expected only the subimage to be opaque")
+	}
+	vimg := img.VarianceImage()
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			exp := color.NRGBA64{}
+			if x >= 1 && x < 3 && y >= 1 && y < 3 {
+				// Population variance of {0.25, 0.75} is
+				// 0.0625.
+				exp = color.NRGBA64{R: 0x4000, A: 0xffff}
+			}
+			if act := vimg.NRGBA64At(x, y); act != exp {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", exp, act, x, y)
+			}
+		}
+	}
+}
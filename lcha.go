@@ -0,0 +1,165 @@
+// This file defines the LChA type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// An LChA is an in-memory image whose At method returns accumcolor.LChA
+// values.
+type LChA struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.LChA
+	// Stride is the Pix stride (in accumcolor.LChAs) between vertically
+	// adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewLChA returns a new LChA image with the given bounds.
+func NewLChA(r image.Rectangle) *LChA {
+	return &LChA{
+		Pix:    make([]accumcolor.LChA, pixelBufferLength(1, r, "LChA")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *LChA) At(x, y int) color.Color {
+	return p.LChAAt(x, y)
+}
+
+// LChAAt returns the color of the pixel at (x, y) as an accumcolor.LChA.
+func (p *LChA) LChAAt(x, y int) accumcolor.LChA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.LChA{}
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// ColorfulAt returns the color of the pixel at (x, y) as a fully opaque
+// colorful.Color (from the go-colorful package).
+func (p *LChA) ColorfulAt(x, y int) colorful.Color {
+	return p.LChAAt(x, y).Colorful()
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *LChA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *LChA) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the LChA's color model (always
+// accumcolor.LChAModel).
+func (p *LChA) ColorModel() color.Model {
+	return accumcolor.LChAModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *LChA) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
+			if clr.Tally == 0 || clr.Alpha != 255*clr.Tally {
+				return false
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *LChA) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.LChAAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *LChA) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LChAModel.Convert(c).(accumcolor.LChA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *LChA) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LChAModel.Convert(c).(accumcolor.LChA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SetLChA sets the pixel at (x, y) to a given color of type
+// accumcolor.LChA.
+func (p *LChA) SetLChA(x, y int, c accumcolor.LChA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddLChA accumulates a given color of type accumcolor.LChA to the pixel
+// at (x, y).
+func (p *LChA) AddLChA(x, y int, c accumcolor.LChA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *LChA) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LChAModel.Convert(c).(accumcolor.LChA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *LChA) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LChAModel.Convert(c).(accumcolor.LChA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *LChA) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &LChA{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &LChA{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,165 @@
+// This file defines the LinearNRGBA type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// A LinearNRGBA is an in-memory image whose At method returns
+// accumcolor.LinearNRGBA values.  Colors are averaged in linear light, which
+// makes a LinearNRGBA well suited to resizing and blending.
+type LinearNRGBA struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.LinearNRGBA
+	// Stride is the Pix stride (in accumcolor.LinearNRGBAs) between
+	// vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewLinearNRGBA returns a new LinearNRGBA image with the given bounds.
+func NewLinearNRGBA(r image.Rectangle) *LinearNRGBA {
+	return &LinearNRGBA{
+		Pix:    make([]accumcolor.LinearNRGBA, pixelBufferLength(1, r, "LinearNRGBA")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *LinearNRGBA) At(x, y int) color.Color {
+	return p.LinearNRGBAAt(x, y)
+}
+
+// LinearNRGBAAt returns the color of the pixel at (x, y) as an
+// accumcolor.LinearNRGBA.
+func (p *LinearNRGBA) LinearNRGBAAt(x, y int) accumcolor.LinearNRGBA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.LinearNRGBA{}
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// ColorNRGBAAt returns the color of the pixel at (x, y) as a color.NRGBA.
+func (p *LinearNRGBA) ColorNRGBAAt(x, y int) color.NRGBA {
+	return p.LinearNRGBAAt(x, y).NRGBA()
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *LinearNRGBA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *LinearNRGBA) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the LinearNRGBA's color model (always
+// accumcolor.LinearNRGBAModel).
+func (p *LinearNRGBA) ColorModel() color.Model {
+	return accumcolor.LinearNRGBAModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *LinearNRGBA) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
+			if clr.Tally == 0 || clr.A != 255*clr.Tally {
+				return false
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *LinearNRGBA) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.LinearNRGBAAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *LinearNRGBA) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LinearNRGBAModel.Convert(c).(accumcolor.LinearNRGBA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *LinearNRGBA) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LinearNRGBAModel.Convert(c).(accumcolor.LinearNRGBA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SetLinearNRGBA sets the pixel at (x, y) to a given color of type
+// accumcolor.LinearNRGBA.
+func (p *LinearNRGBA) SetLinearNRGBA(x, y int, c accumcolor.LinearNRGBA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddLinearNRGBA accumulates a given color of type accumcolor.LinearNRGBA to
+// the pixel at (x, y).
+func (p *LinearNRGBA) AddLinearNRGBA(x, y int, c accumcolor.LinearNRGBA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *LinearNRGBA) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LinearNRGBAModel.Convert(c).(accumcolor.LinearNRGBA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *LinearNRGBA) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.LinearNRGBAModel.Convert(c).(accumcolor.LinearNRGBA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *LinearNRGBA) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &LinearNRGBA{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &LinearNRGBA{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,46 @@
+// This file defines a suite of tests for accumimage.LinearNRGBA.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestLinearNRGBAAdd downscales a black-and-white checkerboard and confirms
+// that the result has the brightness of a 50% mix of light rather than the
+// darker sRGB midpoint.
+func TestLinearNRGBAAdd(t *testing.T) {
+	// Draw a checkerboard into a 2x2 image by accumulating 4x4 pixels.
+	img := NewLinearNRGBA(image.Rect(0, 0, 2, 2))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			c := color.Black
+			if (x+y)%2 == 1 {
+				c = color.White
+			}
+			img.Add(x/2, y/2, c)
+		}
+	}
+	if !img.Opaque() {
+		t.Fatal("expected the image to be opaque")
+	}
+
+	// Confirm that each pixel has the expected color.
+	exp := color.NRGBA{R: 188, G: 188, B: 188, A: 255}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			if act := img.ColorNRGBAAt(x, y); act != exp {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", exp, act, x, y)
+			}
+		}
+	}
+
+	// Confirm that a subimage shares pixels with the original.
+	sub := img.SubImage(image.Rect(1, 1, 2, 2)).(*LinearNRGBA)
+	sub.Set(1, 1, color.Black)
+	if act := img.ColorNRGBAAt(1, 1); act != (color.NRGBA{A: 255}) {
+		t.Fatalf("expected black but saw %v", act)
+	}
+}
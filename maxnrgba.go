@@ -0,0 +1,165 @@
+// This file defines the MaxNRGBA type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// A MaxNRGBA is an in-memory image whose At method returns accumcolor.MaxNRGBA
+// values.  Each pixel represents the per-channel maximum of all colors
+// accumulated at that position.
+type MaxNRGBA struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.MaxNRGBA
+	// Stride is the Pix stride (in accumcolor.MaxNRGBAs) between
+	// vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewMaxNRGBA returns a new MaxNRGBA image with the given bounds.
+func NewMaxNRGBA(r image.Rectangle) *MaxNRGBA {
+	return &MaxNRGBA{
+		Pix:    make([]accumcolor.MaxNRGBA, pixelBufferLength(1, r, "MaxNRGBA")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *MaxNRGBA) At(x, y int) color.Color {
+	return p.MaxNRGBAAt(x, y)
+}
+
+// MaxNRGBAAt returns the color of the pixel at (x, y) as an
+// accumcolor.MaxNRGBA.
+func (p *MaxNRGBA) MaxNRGBAAt(x, y int) accumcolor.MaxNRGBA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.MaxNRGBA{}
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// ColorNRGBAAt returns the color of the pixel at (x, y) as a color.NRGBA.
+func (p *MaxNRGBA) ColorNRGBAAt(x, y int) color.NRGBA {
+	return p.MaxNRGBAAt(x, y).NRGBA()
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *MaxNRGBA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *MaxNRGBA) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the MaxNRGBA's color model (always
+// accumcolor.MaxNRGBAModel).
+func (p *MaxNRGBA) ColorModel() color.Model {
+	return accumcolor.MaxNRGBAModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *MaxNRGBA) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
+			if clr.Tally == 0 || clr.A != 255 {
+				return false
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *MaxNRGBA) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.MaxNRGBAAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *MaxNRGBA) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.MaxNRGBAModel.Convert(c).(accumcolor.MaxNRGBA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *MaxNRGBA) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.MaxNRGBAModel.Convert(c).(accumcolor.MaxNRGBA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SetMaxNRGBA sets the pixel at (x, y) to a given color of type
+// accumcolor.MaxNRGBA.
+func (p *MaxNRGBA) SetMaxNRGBA(x, y int, c accumcolor.MaxNRGBA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddMaxNRGBA accumulates a given color of type accumcolor.MaxNRGBA to the
+// pixel at (x, y).
+func (p *MaxNRGBA) AddMaxNRGBA(x, y int, c accumcolor.MaxNRGBA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *MaxNRGBA) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.MaxNRGBAModel.Convert(c).(accumcolor.MaxNRGBA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *MaxNRGBA) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.MaxNRGBAModel.Convert(c).(accumcolor.MaxNRGBA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *MaxNRGBA) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &MaxNRGBA{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &MaxNRGBA{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,41 @@
+// This file defines a suite of tests for accumimage.MaxNRGBA.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestMaxNRGBAAdd lightens pixels in a subimage with a series of colors and
+// checks that each pixel of the original image holds the per-channel maximum.
+func TestMaxNRGBAAdd(t *testing.T) {
+	img := NewMaxNRGBA(image.Rect(0, 0, 4, 4))
+	sub := img.SubImage(image.Rect(1, 1, 4, 4)).(*MaxNRGBA)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			sub.Add(x, y, color.NRGBA{R: uint8(x * 10), G: 5, B: 0, A: 255})
+			sub.Add(x, y, color.NRGBA{R: 15, G: uint8(y * 10), B: 0, A: 255})
+		}
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			exp := color.NRGBA{}
+			if x >= 1 && y >= 1 {
+				exp.R = uint8(x * 10)
+				if exp.R < 15 {
+					exp.R = 15
+				}
+				exp.G = uint8(y * 10)
+				exp.A = 255
+			}
+			if act := img.ColorNRGBAAt(x, y); act != exp {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", exp, act, x, y)
+			}
+		}
+	}
+	if !sub.Opaque() {
+		t.Fatal("expected the subimage to be opaque")
+	}
+}
@@ -0,0 +1,292 @@
+// This file defines the MedianNRGBA type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+)
+
+// A MedianNRGBA is an in-memory image that retains every color sample
+// accumulated at each pixel.  Its At method returns each pixel's per-channel
+// median as a color.NRGBA.  Unlike a mean, a median is not disturbed by a
+// small number of outliers such as satellite trails, hot pixels, or passers-by
+// in a stack of long exposures.  The Percentile method generalizes the median
+// to arbitrary percentiles.
+type MedianNRGBA struct {
+	// Samples holds each pixel's samples.  The samples accumulated at
+	// (x, y) are Samples[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Samples [][]color.NRGBA
+	// Stride is the Samples stride (in pixels) between vertically
+	// adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewMedianNRGBA returns a new MedianNRGBA image with the given bounds.
+func NewMedianNRGBA(r image.Rectangle) *MedianNRGBA {
+	return &MedianNRGBA{
+		Samples: make([][]color.NRGBA, pixelBufferLength(1, r, "MedianNRGBA")),
+		Stride:  r.Dx(),
+		Rect:    r,
+	}
+}
+
+// At returns the per-channel median of the pixel at (x, y) as a color.Color.
+func (p *MedianNRGBA) At(x, y int) color.Color {
+	return p.PercentileAt(x, y, 50.0)
+}
+
+// PixOffset returns the index of the element of Samples that corresponds to
+// the pixel at (x, y).
+func (p *MedianNRGBA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// TallyAt returns the number of samples accumulated at (x, y).
+func (p *MedianNRGBA) TallyAt(x, y int) uint64 {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return 0
+	}
+	return uint64(len(p.Samples[p.PixOffset(x, y)]))
+}
+
+// selectNth partially sorts vals in place so that vals[k] is the kth smallest
+// (0-based) value, every value before it is no larger, and every value after
+// it is no smaller.  It returns vals[k].
+func selectNth(vals []uint8, k int) uint8 {
+	lo, hi := 0, len(vals)-1
+	for lo < hi {
+		// Partition around the median of the first, middle, and last
+		// values.
+		mid := lo + (hi-lo)/2
+		a, b, c := vals[lo], vals[mid], vals[hi]
+		var pivot uint8
+		switch {
+		case (a <= b) == (b <= c):
+			pivot = b
+		case (b <= a) == (a <= c):
+			pivot = a
+		default:
+			pivot = c
+		}
+		i, j := lo, hi
+		for i <= j {
+			for vals[i] < pivot {
+				i++
+			}
+			for vals[j] > pivot {
+				j--
+			}
+			if i <= j {
+				vals[i], vals[j] = vals[j], vals[i]
+				i++
+				j--
+			}
+		}
+		switch {
+		case k <= j:
+			hi = j
+		case k >= i:
+			lo = i
+		default:
+			return vals[k]
+		}
+	}
+	return vals[k]
+}
+
+// channelPercentile returns the value at a given fractional rank in a list of
+// channel values, interpolating linearly between adjacent ranks.  It reorders
+// vals in the process.
+func channelPercentile(vals []uint8, rank float64) float64 {
+	lo := int(rank)
+	frac := rank - float64(lo)
+	loVal := float64(selectNth(vals, lo))
+	if frac == 0.0 || lo+1 >= len(vals) {
+		return loVal
+	}
+
+	// After selection, the next-larger value is the smallest value that
+	// follows vals[lo].
+	hiVal := vals[lo+1]
+	for _, v := range vals[lo+2:] {
+		if v < hiVal {
+			hiVal = v
+		}
+	}
+	return loVal + (float64(hiVal)-loVal)*frac
+}
+
+// checkPercentile panics if a percentile lies outside [0, 100] or is NaN.
+func checkPercentile(pct float64) {
+	if !(pct >= 0.0 && pct <= 100.0) {
+		panic("accumimage: percentile is not in [0, 100]")
+	}
+}
+
+// channel returns a given channel of a color.NRGBA, numbered from 0 for red
+// through 3 for alpha.
+func channel(c color.NRGBA, ch int) uint8 {
+	switch ch {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	case 2:
+		return c.B
+	default:
+		return c.A
+	}
+}
+
+// percentileAt implements PercentileAt, using buf as scratch space for
+// selecting values from each channel.  It returns the percentile and buf,
+// which it enlarges as needed so that the caller can reuse it.
+func (p *MedianNRGBA) percentileAt(x, y int, pct float64, buf []uint8) (color.NRGBA, []uint8) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return color.NRGBA{}, buf
+	}
+	samples := p.Samples[p.PixOffset(x, y)]
+	n := len(samples)
+	if n == 0 {
+		return color.NRGBA{}, buf
+	}
+	if cap(buf) < n {
+		buf = make([]uint8, n)
+	}
+	buf = buf[:n]
+	rank := pct / 100.0 * float64(n-1)
+
+	// Select the value at the given rank directly from each channel's
+	// samples.
+	var vals [4]uint8
+	for ch := range vals {
+		for i, s := range samples {
+			buf[i] = channel(s, ch)
+		}
+		vals[ch] = uint8(channelPercentile(buf, rank) + 0.5)
+	}
+	return color.NRGBA{R: vals[0], G: vals[1], B: vals[2], A: vals[3]}, buf
+}
+
+// PercentileAt returns the per-channel percentile pct (0 <= pct <= 100) of the
+// samples accumulated at (x, y).  Percentiles that fall between two samples
+// are linearly interpolated.  PercentileAt returns a fully transparent color
+// if no samples have been accumulated at (x, y).  It panics if pct lies
+// outside [0, 100] or is NaN.
+func (p *MedianNRGBA) PercentileAt(x, y int, pct float64) color.NRGBA {
+	checkPercentile(pct)
+	c, _ := p.percentileAt(x, y, pct, nil)
+	return c
+}
+
+// Percentile returns an image in which each pixel is the per-channel
+// percentile pct (0 <= pct <= 100) of the samples accumulated at the
+// corresponding pixel of p.  Pixels at which no samples were accumulated are
+// fully transparent.  Percentile panics if pct lies outside [0, 100] or is
+// NaN.
+func (p *MedianNRGBA) Percentile(pct float64) *image.NRGBA {
+	checkPercentile(pct)
+	img := image.NewNRGBA(p.Rect)
+	var buf []uint8
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+			var c color.NRGBA
+			c, buf = p.percentileAt(x, y, pct, buf)
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// Median returns an image in which each pixel is the per-channel median of
+// the samples accumulated at the corresponding pixel of p.  Pixels at which no
+// samples were accumulated are fully transparent.
+func (p *MedianNRGBA) Median() *image.NRGBA {
+	return p.Percentile(50.0)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *MedianNRGBA) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the MedianNRGBA's color model (always color.NRGBAModel).
+func (p *MedianNRGBA) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *MedianNRGBA) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+			if p.PercentileAt(x, y, 50.0).A != 255 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// RGBA64At returns the per-channel median of the pixel at (x, y) as a
+// color.RGBA64.
+func (p *MedianNRGBA) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.PercentileAt(x, y, 50.0).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set discards all samples accumulated at (x, y) and replaces them with a
+// single sample of a given color of any type.
+func (p *MedianNRGBA) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	c1 := color.NRGBAModel.Convert(c).(color.NRGBA)
+	i := p.PixOffset(x, y)
+	p.Samples[i] = append(p.Samples[i][:0], c1)
+}
+
+// Add accumulates a sample of a given color of any type to the pixel at
+// (x, y).
+func (p *MedianNRGBA) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	c1 := color.NRGBAModel.Convert(c).(color.NRGBA)
+	i := p.PixOffset(x, y)
+	p.Samples[i] = append(p.Samples[i], c1)
+}
+
+// SetRGBA64 discards all samples accumulated at (x, y) and replaces them with
+// a single sample of a given color of type color.RGBA64.
+func (p *MedianNRGBA) SetRGBA64(x, y int, c color.RGBA64) {
+	p.Set(x, y, c)
+}
+
+// AddRGBA64 accumulates a sample of a given color of type color.RGBA64 to the
+// pixel at (x, y).
+func (p *MedianNRGBA) AddRGBA64(x, y int, c color.RGBA64) {
+	p.Add(x, y, c)
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *MedianNRGBA) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Samples[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &MedianNRGBA{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &MedianNRGBA{
+		Samples: p.Samples[i:],
+		Stride:  p.Stride,
+		Rect:    r,
+	}
+}
@@ -0,0 +1,106 @@
+// This file defines a suite of tests for accumimage.MedianNRGBA.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// TestMedianNRGBAOutliers stacks a number of frames, a few of which contain
+// outliers, and confirms that the median ignores the outliers.
+func TestMedianNRGBAOutliers(t *testing.T) {
+	img := NewMedianNRGBA(image.Rect(0, 0, 2, 2))
+	base := color.NRGBA{R: 20, G: 40, B: 60, A: 255}
+	for i := 0; i < 9; i++ {
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 2; x++ {
+				c := base
+				c.R += uint8(i % 3) // Slight noise
+				if i == 4 && x == 1 {
+					c = color.NRGBA{R: 255, G: 255, B: 255, A: 255} // Outlier
+				}
+				img.Add(x, y, c)
+			}
+		}
+	}
+	exp := color.NRGBA{R: 21, G: 40, B: 60, A: 255}
+	med := img.Median()
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			if act := med.NRGBAAt(x, y); act != exp {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", exp, act, x, y)
+			}
+			if tally := img.TallyAt(x, y); tally != 9 {
+				t.Fatalf("expected a tally of 9 but saw %d", tally)
+			}
+		}
+	}
+	if !img.Opaque() {
+		t.Fatal("expected the image to be opaque")
+	}
+}
+
+// TestMedianNRGBAPercentile confirms that arbitrary percentiles are
+// interpolated correctly and that empty pixels remain transparent.
+func TestMedianNRGBAPercentile(t *testing.T) {
+	img := NewMedianNRGBA(image.Rect(0, 0, 2, 1))
+	for _, v := range []uint8{40, 10, 30, 20} {
+		img.Add(0, 0, color.NRGBA{R: v, G: 255 - v, B: v, A: 255})
+	}
+	tests := []struct {
+		pct float64
+		exp color.NRGBA
+	}{
+		{0.0, color.NRGBA{R: 10, G: 215, B: 10, A: 255}},
+		{50.0, color.NRGBA{R: 25, G: 230, B: 25, A: 255}},
+		{100.0, color.NRGBA{R: 40, G: 245, B: 40, A: 255}},
+		{75.0, color.NRGBA{R: 33, G: 238, B: 33, A: 255}},
+	}
+	for _, tst := range tests {
+		if act := img.PercentileAt(0, 0, tst.pct); act != tst.exp {
+			t.Fatalf("expected %v but saw %v at percentile %v", tst.exp, act, tst.pct)
+		}
+	}
+	if act := img.Percentile(90.0).NRGBAAt(1, 0); act != (color.NRGBA{}) {
+		t.Fatalf("expected an empty pixel but saw %v", act)
+	}
+	for _, pct := range []float64{-1.0, 100.5, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected PercentileAt to panic on percentile %v", pct)
+				}
+			}()
+			img.PercentileAt(0, 0, pct)
+		}()
+	}
+
+	// Confirm that a subimage shares samples with the original.
+	sub := img.SubImage(image.Rect(1, 0, 2, 1)).(*MedianNRGBA)
+	sub.Set(1, 0, color.White)
+	if img.TallyAt(1, 0) != 1 {
+		t.Fatal("expected the subimage to share samples with the original image")
+	}
+}
+
+// TestSelectNth confirms that selectNth agrees with sorting.
+func TestSelectNth(t *testing.T) {
+	rng := rand.New(rand.NewSource(12345))
+	for trial := 0; trial < 100; trial++ {
+		vals := make([]uint8, 1+rng.Intn(50))
+		for i := range vals {
+			vals[i] = uint8(rng.Intn(8) * 30) // Many duplicates
+		}
+		sorted := append([]uint8(nil), vals...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		k := rng.Intn(len(vals))
+		if v := selectNth(vals, k); v != sorted[k] {
+			t.Fatalf("expected element %d of %v to be %d but saw %d", k, sorted, sorted[k], v)
+		}
+	}
+}
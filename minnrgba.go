@@ -0,0 +1,165 @@
+// This file defines the MinNRGBA type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// A MinNRGBA is an in-memory image whose At method returns accumcolor.MinNRGBA
+// values.  Each pixel represents the per-channel minimum of all colors
+// accumulated at that position.
+type MinNRGBA struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.MinNRGBA
+	// Stride is the Pix stride (in accumcolor.MinNRGBAs) between
+	// vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewMinNRGBA returns a new MinNRGBA image with the given bounds.
+func NewMinNRGBA(r image.Rectangle) *MinNRGBA {
+	return &MinNRGBA{
+		Pix:    make([]accumcolor.MinNRGBA, pixelBufferLength(1, r, "MinNRGBA")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *MinNRGBA) At(x, y int) color.Color {
+	return p.MinNRGBAAt(x, y)
+}
+
+// MinNRGBAAt returns the color of the pixel at (x, y) as an
+// accumcolor.MinNRGBA.
+func (p *MinNRGBA) MinNRGBAAt(x, y int) accumcolor.MinNRGBA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.MinNRGBA{}
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// ColorNRGBAAt returns the color of the pixel at (x, y) as a color.NRGBA.
+func (p *MinNRGBA) ColorNRGBAAt(x, y int) color.NRGBA {
+	return p.MinNRGBAAt(x, y).NRGBA()
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *MinNRGBA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *MinNRGBA) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the MinNRGBA's color model (always
+// accumcolor.MinNRGBAModel).
+func (p *MinNRGBA) ColorModel() color.Model {
+	return accumcolor.MinNRGBAModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *MinNRGBA) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
+			if clr.Tally == 0 || clr.A != 255 {
+				return false
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *MinNRGBA) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.MinNRGBAAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *MinNRGBA) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.MinNRGBAModel.Convert(c).(accumcolor.MinNRGBA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *MinNRGBA) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.MinNRGBAModel.Convert(c).(accumcolor.MinNRGBA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SetMinNRGBA sets the pixel at (x, y) to a given color of type
+// accumcolor.MinNRGBA.
+func (p *MinNRGBA) SetMinNRGBA(x, y int, c accumcolor.MinNRGBA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddMinNRGBA accumulates a given color of type accumcolor.MinNRGBA to the
+// pixel at (x, y).
+func (p *MinNRGBA) AddMinNRGBA(x, y int, c accumcolor.MinNRGBA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *MinNRGBA) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.MinNRGBAModel.Convert(c).(accumcolor.MinNRGBA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *MinNRGBA) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.MinNRGBAModel.Convert(c).(accumcolor.MinNRGBA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *MinNRGBA) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &MinNRGBA{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &MinNRGBA{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
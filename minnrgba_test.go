@@ -0,0 +1,36 @@
+// This file defines a suite of tests for accumimage.MinNRGBA.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestMinNRGBAAdd darkens a row of pixels with a series of colors and checks
+// that each pixel holds the per-channel minimum.
+func TestMinNRGBAAdd(t *testing.T) {
+	img := NewMinNRGBA(image.Rect(0, 0, 3, 1))
+	img.Add(0, 0, color.NRGBA{R: 90, G: 200, B: 30, A: 255})
+	img.Add(0, 0, color.NRGBA{R: 80, G: 210, B: 40, A: 255})
+	img.Add(1, 0, color.Transparent)
+	exp := []color.NRGBA{
+		{R: 80, G: 200, B: 30, A: 255},
+		{},
+		{},
+	}
+	for x, e := range exp {
+		if act := img.ColorNRGBAAt(x, 0); act != e {
+			t.Fatalf("expected %v but saw %v at (%d, 0)", e, act, x)
+		}
+	}
+
+	// Pixels 1 and 2 differ only in whether anything was accumulated.
+	if img.MinNRGBAAt(1, 0).Tally != 1 || img.MinNRGBAAt(2, 0).Tally != 0 {
+		t.Fatal("expected only pixel 2 to have a zero tally")
+	}
+	if img.Opaque() {
+		t.Fatal("expected the image not to be opaque")
+	}
+}
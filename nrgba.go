@@ -3,6 +3,7 @@
 package accumimage
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 	"math/bits"
@@ -24,6 +25,11 @@ type NRGBA struct {
 	Stride int
 	// Rect is the image's bounds.
 	Rect image.Rectangle
+	// Saturate, if true, makes Add, AddNRGBA, and AddRGBA64 discard any
+	// color whose accumulation would overflow a pixel.  The pixel thereby
+	// saturates at its current average instead of wrapping around to
+	// garbage.
+	Saturate bool
 }
 
 // mul3NonNeg returns (x * y * z), unless at least one argument is negative or
@@ -155,6 +161,10 @@ func (p *NRGBA) Add(x, y int, c color.Color) {
 	}
 	i := p.PixOffset(x, y)
 	c1 := accumcolor.NRGBAModel.Convert(c).(accumcolor.NRGBA)
+	if p.Saturate {
+		p.addSaturating(i, c1)
+		return
+	}
 	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
 	s[0] += c1.R
 	s[1] += c1.G
@@ -163,6 +173,22 @@ func (p *NRGBA) Add(x, y int, c color.Color) {
 	s[4] += c1.Tally
 }
 
+// addSaturating accumulates a given color to the pixel starting at Pix[i]
+// unless doing so would overflow the pixel, in which case it leaves the pixel
+// unmodified.
+func (p *NRGBA) addSaturating(i int, c accumcolor.NRGBA) {
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	sum := accumcolor.NRGBA{R: s[0], G: s[1], B: s[2], A: s[3], Tally: s[4]}
+	if sum.AddChecked(c) != nil {
+		return
+	}
+	s[0] = sum.R
+	s[1] = sum.G
+	s[2] = sum.B
+	s[3] = sum.A
+	s[4] = sum.Tally
+}
+
 // SetNRGBA sets the pixel at (x, y) to a given color of type
 // accumcolor.NRGBA.
 func (p *NRGBA) SetNRGBA(x, y int, c accumcolor.NRGBA) {
@@ -185,6 +211,10 @@ func (p *NRGBA) AddNRGBA(x, y int, c accumcolor.NRGBA) {
 		return
 	}
 	i := p.PixOffset(x, y)
+	if p.Saturate {
+		p.addSaturating(i, c)
+		return
+	}
 	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
 	s[0] += c.R
 	s[1] += c.G
@@ -193,6 +223,58 @@ func (p *NRGBA) AddNRGBA(x, y int, c accumcolor.NRGBA) {
 	s[4] += c.Tally
 }
 
+// Sub removes a given color of any type, previously accumulated with Add, from
+// the pixel at (x, y).  It returns an error and leaves the pixel unmodified if
+// the color cannot be removed (see accumcolor.NRGBA.Sub).
+func (p *NRGBA) Sub(x, y int, c color.Color) error {
+	return p.SubNRGBA(x, y, accumcolor.NRGBAModel.Convert(c).(accumcolor.NRGBA))
+}
+
+// SubNRGBA removes a given color of type accumcolor.NRGBA, previously
+// accumulated with AddNRGBA, from the pixel at (x, y).  It returns an error
+// and leaves the pixel unmodified if the color cannot be removed (see
+// accumcolor.NRGBA.Sub).
+func (p *NRGBA) SubNRGBA(x, y int, c accumcolor.NRGBA) error {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return nil
+	}
+	clr := p.NRGBAAt(x, y)
+	if err := clr.Sub(c); err != nil {
+		return err
+	}
+	p.SetNRGBA(x, y, clr)
+	return nil
+}
+
+// Remove subtracts each pixel of src from the corresponding pixel of p,
+// undoing an earlier accumulation of the same image.  Pixels of src that lie
+// outside p's bounds are ignored.  If any pixel cannot be removed, Remove
+// returns an error wrapping the one returned by accumcolor.NRGBA.Sub and
+// leaves p unmodified.
+func (p *NRGBA) Remove(src image.Image) error {
+	// Compute all differences before modifying any pixels.
+	r := p.Rect.Intersect(src.Bounds())
+	diffs := make([]accumcolor.NRGBA, 0, r.Dx()*r.Dy())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			clr := p.NRGBAAt(x, y)
+			if err := clr.Sub(src.At(x, y)); err != nil {
+				return fmt.Errorf("accumimage: cannot remove color at (%d, %d): %w", x, y, err)
+			}
+			diffs = append(diffs, clr)
+		}
+	}
+
+	// Store the differences.
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			p.SetNRGBA(x, y, diffs[0])
+			diffs = diffs[1:]
+		}
+	}
+	return nil
+}
+
 // SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
 func (p *NRGBA) SetRGBA64(x, y int, c color.RGBA64) {
 	if !(image.Point{x, y}.In(p.Rect)) {
@@ -225,6 +307,16 @@ func (p *NRGBA) AddRGBA64(x, y int, c color.RGBA64) {
 		b = (b * 0xffff) / a
 	}
 	i := p.PixOffset(x, y)
+	if p.Saturate {
+		p.addSaturating(i, accumcolor.NRGBA{
+			R:     uint64(r >> 8),
+			G:     uint64(g >> 8),
+			B:     uint64(b >> 8),
+			A:     uint64(a >> 8),
+			Tally: 1,
+		})
+		return
+	}
 	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
 	s[0] += uint64(r >> 8)
 	s[1] += uint64(g >> 8)
@@ -233,6 +325,92 @@ func (p *NRGBA) AddRGBA64(x, y int, c color.RGBA64) {
 	s[4]++
 }
 
+// SetImage sets each pixel in r to the color of the corresponding pixel of
+// src, where sp in src corresponds to r.Min, as in the standard library's
+// draw.Draw.  r is clipped to the bounds of both p and src.  If src is itself
+// an NRGBA, accumulated colors are copied exactly.  SetImage reads the pixels
+// of the most common standard-library image types directly, which is much
+// faster than calling Set on each pixel.
+func (p *NRGBA) SetImage(r image.Rectangle, src image.Image, sp image.Point) {
+	r, sp = clipImage(p.Rect, r, src, sp)
+	d := sp.Sub(r.Min)
+	switch src := src.(type) {
+	case *NRGBA:
+		back := processBackward(p, src, r, sp)
+		forEachPoint(r, back, func(x, y int) {
+			p.SetNRGBA(x, y, src.NRGBAAt(x+d.X, y+d.Y))
+		})
+	case *image.NRGBA:
+		// Copy non-alpha-premultiplied colors directly to avoid the
+		// loss of precision incurred by premultiplying them.
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			i := p.PixOffset(r.Min.X, y)
+			j := src.PixOffset(r.Min.X+d.X, y+d.Y)
+			for x := r.Min.X; x < r.Max.X; x++ {
+				s := p.Pix[i : i+5 : i+5]
+				c := src.Pix[j : j+4 : j+4]
+				s[0] = uint64(c[0])
+				s[1] = uint64(c[1])
+				s[2] = uint64(c[2])
+				s[3] = uint64(c[3])
+				s[4] = 1
+				i += 5
+				j += 4
+			}
+		}
+	default:
+		forEachRGBA64(r, src, sp, p.SetRGBA64)
+	}
+}
+
+// AddImage accumulates the color of each pixel of src to the corresponding
+// pixel in r, where sp in src corresponds to r.Min, as in the standard
+// library's draw.Draw.  r is clipped to the bounds of both p and src.  If src
+// is itself an NRGBA, accumulated colors are added exactly.  Otherwise,
+// AddImage reads the pixels of the most common standard-library image types
+// directly, which is much faster than calling Add on each pixel.
+func (p *NRGBA) AddImage(r image.Rectangle, src image.Image, sp image.Point) {
+	r, sp = clipImage(p.Rect, r, src, sp)
+	d := sp.Sub(r.Min)
+	switch src := src.(type) {
+	case *NRGBA:
+		back := processBackward(p, src, r, sp)
+		forEachPoint(r, back, func(x, y int) {
+			p.AddNRGBA(x, y, src.NRGBAAt(x+d.X, y+d.Y))
+		})
+	case *image.NRGBA:
+		// Copy non-alpha-premultiplied colors directly to avoid the
+		// loss of precision incurred by premultiplying them.
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			i := p.PixOffset(r.Min.X, y)
+			j := src.PixOffset(r.Min.X+d.X, y+d.Y)
+			for x := r.Min.X; x < r.Max.X; x++ {
+				c := src.Pix[j : j+4 : j+4]
+				if p.Saturate {
+					p.addSaturating(i, accumcolor.NRGBA{
+						R:     uint64(c[0]),
+						G:     uint64(c[1]),
+						B:     uint64(c[2]),
+						A:     uint64(c[3]),
+						Tally: 1,
+					})
+				} else {
+					s := p.Pix[i : i+5 : i+5]
+					s[0] += uint64(c[0])
+					s[1] += uint64(c[1])
+					s[2] += uint64(c[2])
+					s[3] += uint64(c[3])
+					s[4]++
+				}
+				i += 5
+				j += 4
+			}
+		}
+	default:
+		forEachRGBA64(r, src, sp, p.AddRGBA64)
+	}
+}
+
 // SubImage returns an image representing the portion of the image p visible
 // through r. The returned value shares pixels with the original image.
 func (p *NRGBA) SubImage(r image.Rectangle) image.Image {
@@ -246,8 +424,9 @@ func (p *NRGBA) SubImage(r image.Rectangle) image.Image {
 	}
 	i := p.PixOffset(r.Min.X, r.Min.Y)
 	return &NRGBA{
-		Pix:    p.Pix[i:],
-		Stride: p.Stride,
-		Rect:   r,
+		Pix:      p.Pix[i:],
+		Stride:   p.Stride,
+		Rect:     r,
+		Saturate: p.Saturate,
 	}
 }
@@ -0,0 +1,219 @@
+// This file defines the NRGBA64 type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// NOTE: Many of the functions and methods in this file were copied verbatim or
+// nearly verbatim from the Go standard library (image/image.go).
+
+// An NRGBA64 is an in-memory image whose At method returns
+// accumcolor.NRGBA64 values.
+type NRGBA64 struct {
+	// Pix holds the image's pixels, in R, G, B, A, Tally order. The pixel
+	// at (x, y) starts at Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)*5].
+	Pix []uint64
+	// Stride is the Pix stride (in uint64s) between vertically adjacent
+	// pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewNRGBA64 returns a new NRGBA64 image with the given bounds.
+func NewNRGBA64(r image.Rectangle) *NRGBA64 {
+	return &NRGBA64{
+		Pix:    make([]uint64, pixelBufferLength(5, r, "NRGBA64")),
+		Stride: 5 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *NRGBA64) At(x, y int) color.Color {
+	return p.NRGBA64At(x, y)
+}
+
+// NRGBA64At returns the color of the pixel at (x, y) as an
+// accumcolor.NRGBA64.
+func (p *NRGBA64) NRGBA64At(x, y int) accumcolor.NRGBA64 {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.NRGBA64{}
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	return accumcolor.NRGBA64{R: s[0], G: s[1], B: s[2], A: s[3], Tally: s[4]}
+}
+
+// ColorNRGBA64At returns the color of the pixel at (x, y) as a
+// color.NRGBA64.
+func (p *NRGBA64) ColorNRGBA64At(x, y int) color.NRGBA64 {
+	return p.NRGBA64At(x, y).NRGBA64()
+}
+
+// PixOffset returns the index of the first element of Pix that corresponds to
+// the pixel at (x, y).
+func (p *NRGBA64) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*5
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *NRGBA64) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the NRGBA64's color model (always
+// accumcolor.NRGBA64Model).
+func (p *NRGBA64) ColorModel() color.Model {
+	return accumcolor.NRGBA64Model
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *NRGBA64) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 3, p.Rect.Dx()*5
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for i := i0; i < i1; i += 5 {
+			tally := p.Pix[i+1]
+			if tally == 0 {
+				return false // No color at this position
+			}
+			if p.Pix[i] != 0xffff*tally {
+				return false // Not fully opaque
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *NRGBA64) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.NRGBA64At(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *NRGBA64) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	c1 := accumcolor.NRGBA64Model.Convert(c).(accumcolor.NRGBA64)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = c1.R
+	s[1] = c1.G
+	s[2] = c1.B
+	s[3] = c1.A
+	s[4] = c1.Tally
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *NRGBA64) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	c1 := accumcolor.NRGBA64Model.Convert(c).(accumcolor.NRGBA64)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += c1.R
+	s[1] += c1.G
+	s[2] += c1.B
+	s[3] += c1.A
+	s[4] += c1.Tally
+}
+
+// SetNRGBA64 sets the pixel at (x, y) to a given color of type
+// accumcolor.NRGBA64.
+func (p *NRGBA64) SetNRGBA64(x, y int, c accumcolor.NRGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = c.R
+	s[1] = c.G
+	s[2] = c.B
+	s[3] = c.A
+	s[4] = c.Tally
+}
+
+// AddNRGBA64 accumulates a given color of type accumcolor.NRGBA64 to the
+// pixel at (x, y).
+func (p *NRGBA64) AddNRGBA64(x, y int, c accumcolor.NRGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += c.R
+	s[1] += c.G
+	s[2] += c.B
+	s[3] += c.A
+	s[4] += c.Tally
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *NRGBA64) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	r, g, b, a := uint32(c.R), uint32(c.G), uint32(c.B), uint32(c.A)
+	if (a != 0) && (a != 0xffff) {
+		r = (r * 0xffff) / a
+		g = (g * 0xffff) / a
+		b = (b * 0xffff) / a
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = uint64(r)
+	s[1] = uint64(g)
+	s[2] = uint64(b)
+	s[3] = uint64(a)
+	s[4] = 1
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at (x, y).
+func (p *NRGBA64) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	r, g, b, a := uint32(c.R), uint32(c.G), uint32(c.B), uint32(c.A)
+	if (a != 0) && (a != 0xffff) {
+		r = (r * 0xffff) / a
+		g = (g * 0xffff) / a
+		b = (b * 0xffff) / a
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += uint64(r)
+	s[1] += uint64(g)
+	s[2] += uint64(b)
+	s[3] += uint64(a)
+	s[4]++
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *NRGBA64) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &NRGBA64{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &NRGBA64{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,80 @@
+// This file defines a suite of tests for accumimage.NRGBA64.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestNRGBA64Add adds together different numbers of 16-bit colors and checks
+// that the averages retain full precision.
+func TestNRGBA64Add(t *testing.T) {
+	// Construct a column of colors.
+	const n = 100
+	img := NewNRGBA64(image.Rect(0, 0, 1, n))
+
+	// Accumulate the most colors to the first pixel, less to the second,
+	// less to the third, and so forth.
+	for i := 0; i < n; i++ {
+		c := color.NRGBA64{
+			R: uint16(i + 0x1000),
+			G: uint16(i + 0x2001),
+			B: uint16(i + 0x3002),
+			A: uint16(i + 0x4003),
+		}
+		for j := 0; j <= i; j++ {
+			img.Add(0, j, c)
+		}
+	}
+
+	// Confirm that each pixel contains the expected color.
+	for i := 0; i < n; i++ {
+		c := img.ColorNRGBA64At(0, i)
+		base := uint16((n + i - 1) / 2)
+		exp := color.NRGBA64{
+			R: base + 0x1000,
+			G: base + 0x2001,
+			B: base + 0x3002,
+			A: base + 0x4003,
+		}
+		if c != exp {
+			t.Fatalf("expected %v but saw %v", exp, c)
+		}
+	}
+}
+
+// TestNRGBA64SubImage modifies values in a subimage and ensures these
+// modifications are reflected in the original image.
+func TestNRGBA64SubImage(t *testing.T) {
+	img1 := NewNRGBA64(image.Rect(3, 3, 13, 13))
+	c1 := color.RGBA64{R: 0x0101, G: 0x0202, B: 0x0303, A: 0xffff}
+	c2 := color.RGBA64{R: 0x0404, G: 0x0505, B: 0x0606, A: 0xffff}
+	for y := 3; y < 13; y++ {
+		for x := 3; x < 13; x++ {
+			img1.SetRGBA64(x, y, c1)
+		}
+	}
+	img2 := img1.SubImage(image.Rect(5, 5, 105, 105)).(*NRGBA64)
+	for y := 5; y < 13; y++ {
+		for x := 5; x < 13; x++ {
+			img2.AddRGBA64(x, y, c2)
+		}
+	}
+	if !img1.Opaque() {
+		t.Fatal("expected the image to be opaque")
+	}
+	for y := 3; y < 13; y++ {
+		for x := 3; x < 13; x++ {
+			exp := c1
+			if x >= 5 && y >= 5 {
+				exp = color.RGBA64{R: 0x0282, G: 0x0383, B: 0x0484, A: 0xffff}
+			}
+			act := img1.RGBA64At(x, y)
+			if act != exp {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", exp, act, x, y)
+			}
+		}
+	}
+}
@@ -3,9 +3,12 @@
 package accumimage
 
 import (
+	"errors"
 	"image"
 	"image/color"
 	"testing"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
 )
 
 // TestNRGBAAdd1 adds a number of colors together and checks the result.  It
@@ -76,3 +79,107 @@ func TestNRGBAAdd2(t *testing.T) {
 		}
 	}
 }
+
+// TestNRGBARemove maintains a sliding window of frames by adding new frames
+// and removing old ones.
+func TestNRGBARemove(t *testing.T) {
+	// Create a sequence of uniformly colored frames.
+	r := image.Rect(0, 0, 4, 3)
+	frames := make([]*image.NRGBA, 5)
+	for i := range frames {
+		frames[i] = image.NewNRGBA(r)
+		v := uint8(i * 50)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				frames[i].SetNRGBA(x, y, color.NRGBA{R: v, G: 255 - v, B: v / 2, A: 255})
+			}
+		}
+	}
+
+	// Average a window of three frames at a time.
+	img := NewNRGBA(r)
+	for i, f := range frames {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				img.Add(x, y, f.At(x, y))
+			}
+		}
+		if i < 2 {
+			continue
+		}
+		if i > 2 {
+			if err := img.Remove(frames[i-3]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		v := uint8((i - 1) * 50)
+		exp := color.NRGBA{R: v, G: 255 - v, B: v / 2, A: 255}
+		if act := img.ColorNRGBAAt(3, 2); act != exp {
+			t.Fatalf("expected %v but saw %v after frame %d", exp, act, i)
+		}
+	}
+
+	// Removing a frame a second time should fail and leave the image
+	// unmodified.
+	for _, f := range frames[2:4] {
+		if err := img.Remove(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := img.NRGBAAt(0, 0)
+	if err := img.Remove(frames[2]); !errors.Is(err, accumcolor.ErrUnderflow) {
+		t.Fatalf("expected ErrUnderflow but saw %v", err)
+	}
+	if after := img.NRGBAAt(0, 0); after != before {
+		t.Fatalf("expected %v but saw %v", before, after)
+	}
+
+	// Removing the accumulated image from itself should clear it.
+	if err := img.Remove(img.SubImage(r)); err != nil {
+		t.Fatal(err)
+	}
+	if c := img.NRGBAAt(1, 1); c != (accumcolor.NRGBA{}) {
+		t.Fatalf("expected %v to be zero", c)
+	}
+}
+
+// TestNRGBASaturate confirms that a saturating NRGBA discards colors that
+// would overflow a pixel instead of wrapping around.
+func TestNRGBASaturate(t *testing.T) {
+	r := image.Rect(0, 0, 2, 1)
+	img := NewNRGBA(r)
+	img.Saturate = true
+	big := accumcolor.NRGBA{R: 100, G: 50, B: 0, A: 255, Tally: 1}
+	if err := big.ScaleChecked(1 << 56); err != nil {
+		t.Fatal(err)
+	}
+	img.AddNRGBA(0, 0, big)
+	img.AddNRGBA(0, 0, big)
+	if act := img.NRGBAAt(0, 0); act != big {
+		t.Fatalf("expected %v but saw %v", big, act)
+	}
+	full := accumcolor.NRGBA{Tally: ^uint64(0)}
+	img.SetNRGBA(0, 0, full)
+	img.Add(0, 0, color.White)
+	img.AddRGBA64(0, 0, color.RGBA64{A: 0xffff})
+	if act := img.NRGBAAt(0, 0); act != full {
+		t.Fatalf("expected %v but saw %v", full, act)
+	}
+	white := image.NewNRGBA(r)
+	for i := range white.Pix {
+		white.Pix[i] = 255
+	}
+	img.AddImage(r, white, image.Point{})
+	if act := img.NRGBAAt(0, 0); act != full {
+		t.Fatalf("expected %v but saw %v", full, act)
+	}
+	img.SetNRGBA(1, 0, accumcolor.NRGBA{})
+
+	// Sub-images should inherit saturation.
+	sub := img.SubImage(image.Rect(1, 0, 2, 1)).(*NRGBA)
+	sub.AddNRGBA(1, 0, big)
+	sub.AddNRGBA(1, 0, big)
+	if act := img.NRGBAAt(1, 0); act != big {
+		t.Fatalf("expected %v but saw %v", big, act)
+	}
+}
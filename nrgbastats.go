@@ -0,0 +1,209 @@
+// This file defines the NRGBAStats type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// An NRGBAStats is an in-memory image whose At method returns
+// accumcolor.NRGBAStats values.  In addition to each pixel's mean color, an
+// NRGBAStats tracks each pixel's variance, which indicates where an average
+// has and has not yet converged.
+type NRGBAStats struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.NRGBAStats
+	// Stride is the Pix stride (in accumcolor.NRGBAStats values) between
+	// vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewNRGBAStats returns a new NRGBAStats image with the given bounds.
+func NewNRGBAStats(r image.Rectangle) *NRGBAStats {
+	return &NRGBAStats{
+		Pix:    make([]accumcolor.NRGBAStats, pixelBufferLength(1, r, "NRGBAStats")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *NRGBAStats) At(x, y int) color.Color {
+	return p.NRGBAStatsAt(x, y)
+}
+
+// NRGBAStatsAt returns the color of the pixel at (x, y) as an
+// accumcolor.NRGBAStats.
+func (p *NRGBAStats) NRGBAStatsAt(x, y int) accumcolor.NRGBAStats {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.NRGBAStats{}
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// ColorNRGBAAt returns the mean color of the pixel at (x, y) as a
+// color.NRGBA.
+func (p *NRGBAStats) ColorNRGBAAt(x, y int) color.NRGBA {
+	return p.NRGBAStatsAt(x, y).NRGBA()
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *NRGBAStats) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *NRGBAStats) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the NRGBAStats's color model (always
+// accumcolor.NRGBAStatsModel).
+func (p *NRGBAStats) ColorModel() color.Model {
+	return accumcolor.NRGBAStatsModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *NRGBAStats) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
+			if clr.Tally == 0 || clr.A.Mean != 255.0 {
+				return false
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *NRGBAStats) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.NRGBAStatsAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *NRGBAStats) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.NRGBAStatsModel.Convert(c).(accumcolor.NRGBAStats)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *NRGBAStats) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.NRGBAStatsModel.Convert(c).(accumcolor.NRGBAStats)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// SetNRGBAStats sets the pixel at (x, y) to a given color of type
+// accumcolor.NRGBAStats.
+func (p *NRGBAStats) SetNRGBAStats(x, y int, c accumcolor.NRGBAStats) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddNRGBAStats accumulates a given color of type accumcolor.NRGBAStats to the
+// pixel at (x, y).
+func (p *NRGBAStats) AddNRGBAStats(x, y int, c accumcolor.NRGBAStats) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *NRGBAStats) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.NRGBAStatsModel.Convert(c).(accumcolor.NRGBAStats)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *NRGBAStats) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.NRGBAStatsModel.Convert(c).(accumcolor.NRGBAStats)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// varianceToUint16 maps a variance to the range [0, 65535], given the
+// variance that should map to 65535.  Larger variances are clamped.
+func varianceToUint16(v, max float64) uint16 {
+	if v >= max {
+		return 0xffff
+	}
+	return uint16(v/max*65535.0 + 0.5)
+}
+
+// populationVariance converts the unbiased sample variance of n values to
+// their population variance.
+func populationVariance(v float64, n uint64) float64 {
+	return v * float64(n-1) / float64(n)
+}
+
+// VarianceImage returns a variance map of the image.  Each pixel's red,
+// green, and blue channels represent the population variance of the
+// corresponding channel of p, scaled so that 0xffff corresponds to the
+// variance of an equal number of values at 0 and 255, the largest population
+// variance possible.  Pixels that have accumulated no color are transparent in
+// the variance map; all other pixels are opaque.
+func (p *NRGBAStats) VarianceImage() *image.NRGBA64 {
+	const maxVar = 127.5 * 127.5
+	img := image.NewNRGBA64(p.Rect)
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+			c := p.Pix[p.PixOffset(x, y)]
+			if c.Tally == 0 {
+				continue
+			}
+			r, g, b, _ := c.Variance()
+			img.SetNRGBA64(x, y, color.NRGBA64{
+				R: varianceToUint16(populationVariance(r, c.Tally), maxVar),
+				G: varianceToUint16(populationVariance(g, c.Tally), maxVar),
+				B: varianceToUint16(populationVariance(b, c.Tally), maxVar),
+				A: 0xffff,
+			})
+		}
+	}
+	return img
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *NRGBAStats) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &NRGBAStats{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &NRGBAStats{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,53 @@
+// This file defines a suite of tests for accumimage.NRGBAStats.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestNRGBAStatsVarianceImage accumulates noisy colors into one pixel and
+// constant colors into another and confirms that the variance map
+// distinguishes them.
+func TestNRGBAStatsVarianceImage(t *testing.T) {
+	img := NewNRGBAStats(image.Rect(0, 0, 3, 1))
+	for i := 0; i < 100; i++ {
+		v := uint8(0)
+		if i%2 == 1 {
+			v = 255
+		}
+		img.Add(0, 0, color.NRGBA{R: v, G: 128, B: v, A: 255})
+		img.Add(1, 0, color.NRGBA{R: 50, G: 100, B: 150, A: 255})
+	}
+
+	// Check the mean colors.
+	exp := color.NRGBA{R: 128, G: 128, B: 128, A: 255}
+	if act := img.ColorNRGBAAt(0, 0); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+	exp = color.NRGBA{R: 50, G: 100, B: 150, A: 255}
+	if act := img.ColorNRGBAAt(1, 0); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+
+	// Check the variance map.
+	vimg := img.VarianceImage()
+	expVar := []color.NRGBA64{
+		{R: 0xffff, G: 0, B: 0xffff, A: 0xffff},
+		{R: 0, G: 0, B: 0, A: 0xffff},
+		{},
+	}
+	for x, e := range expVar {
+		if act := vimg.NRGBA64At(x, 0); act != e {
+			t.Fatalf("expected %v but saw %v at (%d, 0)", e, act, x)
+		}
+	}
+
+	// Check the standard error.
+	r, g, _, _ := img.NRGBAStatsAt(0, 0).StandardError()
+	if r < 12.0 || r > 13.5 || g != 0.0 {
+		t.Fatalf("unexpected standard errors %v and %v", r, g)
+	}
+}
@@ -0,0 +1,100 @@
+// This file defines a suite of tests for accumimage.OkLabA.
+
+package accumimage
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// TestOkLabAAdd adds a number of colors together and checks the result.  It
+// uses NewOkLabA and OkLabA's Add and OkLabAAt methods.
+func TestOkLabAAdd(t *testing.T) {
+	// Construct a row of colors.
+	const wd = 256
+	img := NewOkLabA(image.Rect(0, 0, wd, 1))
+
+	// Repeatedly add the same color to each pixel.
+	const n = 10
+	c := accumcolor.OkLabA{
+		L:     0.6,
+		A:     0.1,
+		B:     -0.1,
+		Alpha: 200,
+		Tally: 1,
+	}
+	for j := 0; j < n; j++ {
+		for i := 0; i < wd; i++ {
+			img.Add(i, 0, c)
+		}
+	}
+
+	// Confirm that each value is as expected.
+	approxEqual := func(a, b float64) bool {
+		return math.Abs(a-b) < 1e-6
+	}
+	for i := 0; i < wd; i++ {
+		c := img.OkLabAAt(i, 0)
+		if c.Tally != n {
+			t.Fatalf("incorrect tally at position (%d, 0)", i)
+		}
+		if c.Alpha != n*200 ||
+			!approxEqual(c.L, 0.6*n) ||
+			!approxEqual(c.A, 0.1*n) ||
+			!approxEqual(c.B, -0.1*n) {
+			t.Fatalf("incorrect color at position (%d, 0)", i)
+		}
+	}
+}
+
+// TestOkLabASubImage modifies values in a subimage and ensures these
+// modifications are reflected in the original image.
+func TestOkLabASubImage(t *testing.T) {
+	// Construct a square image of a single color.
+	c1 := accumcolor.OkLabA{L: 0.8, A: -0.2, B: 0.3, Alpha: 255, Tally: 1}
+	rect1 := image.Rect(3, 3, 13, 13)
+	img1 := NewOkLabA(rect1)
+	for y := rect1.Min.Y; y < rect1.Max.Y; y++ {
+		for x := rect1.Min.X; x < rect1.Max.X; x++ {
+			img1.SetOkLabA(x, y, c1)
+		}
+	}
+	if !img1.Opaque() {
+		t.Fatal("expected the image to be opaque")
+	}
+
+	// Assign all pixels in a square subimage a second color.
+	c2 := accumcolor.OkLabA{L: 0.2, A: 0.1, B: 0.1, Alpha: 150, Tally: 1}
+	img2 := img1.SubImage(image.Rect(5, 5, 105, 105)).(*OkLabA)
+	rect2 := img2.Rect
+	for y := rect2.Min.Y; y < rect2.Max.Y; y++ {
+		for x := rect2.Min.X; x < rect2.Max.X; x++ {
+			img2.SetOkLabA(x, y, c2)
+		}
+	}
+	if img1.Opaque() {
+		t.Fatal("expected the image not to be opaque")
+	}
+
+	// Confirm that all pixels have the correct value.
+	for y := rect1.Min.Y; y < rect1.Max.Y; y++ {
+		for x := rect1.Min.X; x < rect1.Max.X; x++ {
+			clr := img1.OkLabAAt(x, y)
+			switch clr {
+			case c1:
+				if y >= 5 && x >= 5 {
+					t.Fatalf("incorrect color C1 at (%d, %d)", x, y)
+				}
+			case c2:
+				if y < 5 || x < 5 {
+					t.Fatalf("incorrect color C2 at (%d, %d)", x, y)
+				}
+			default:
+				t.Fatalf("unexpected color %v at (%d, %d)", clr, x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+/*
+Package raster draws anti-aliased shapes onto accumulating images.
+
+A Rasterizer computes the fraction of each pixel that a shape covers and
+accumulates the shape's color onto an accumimage.AccumImage with a weight
+proportional to that coverage.  Unlike compositing, this averages overlapping
+shapes: a pixel covered by many translucent strokes takes on their average
+color rather than growing ever more opaque, and its tally records how much
+ink it received.
+
+The Line, Polyline, QuadBezier, and CubicBezier methods stroke lines and
+curves of a given width.  Each stroke is rasterized in full before it is
+accumulated, so a pixel that a stroke crosses more than once, such as at a
+polyline's joints, receives the stroke's color only once.
+
+A Path describes a shape as a sequence of subpaths built from line segments and
+quadratic and cubic Bézier curves.  The Stroke method strokes a Path, and the
+Fill method fills it according to either the NonZero or the EvenOdd fill rule,
+in the spirit of golang.org/x/image/vector.  Fill computes the area of each
+pixel that a Path covers and accumulates the Path's color with a weight
+proportional to that area, so overlapping translucent polygons average rather
+than stacking opacity.
+
+Colors are accumulated through accumimage.Weighted.  Images whose pixels
+maintain fractional tallies, such as accumimage.WeightedNRGBA,
+accumimage.WeightedLabA, and an accumimage.Image of accumcolor.WeightedNRGBA,
+receive each pixel's coverage as its weight.  Images whose pixels maintain
+integer tallies, such as accumimage.NRGBA, accumimage.LabA, and
+accumimage.Gray, receive integer weights that are proportional to coverage,
+with full coverage corresponding to a weight of CoverageLevels.  NewRasterizer
+rejects images that cannot accumulate weighted colors, such as
+accumimage.MedianNRGBA.
+*/
+package raster
@@ -0,0 +1,182 @@
+// This file defines methods for filling paths.
+
+package raster
+
+import (
+	"image/color"
+	"math"
+)
+
+// A FillRule determines which regions a self-intersecting or nested Path
+// encloses.
+type FillRule int
+
+const (
+	// NonZero fills every region around which the Path winds a nonzero
+	// number of times.
+	NonZero FillRule = iota
+	// EvenOdd fills every region around which the Path winds an odd
+	// number of times.
+	EvenOdd
+)
+
+// coverageEpsilon is the amount of rounding error tolerated when computing
+// coverage.  Coverage within coverageEpsilon of 0 or 1 is rounded to 0 or 1.
+const coverageEpsilon = 1e-4
+
+// coverage maps an accumulated, signed area to a coverage in [0, 1]
+// according to the fill rule.
+func (r FillRule) coverage(a float64) float64 {
+	a = math.Abs(a)
+	if r == EvenOdd {
+		a -= 2.0 * math.Floor(a/2.0)
+		if a > 1.0 {
+			a = 2.0 - a
+		}
+	}
+	switch {
+	case a < coverageEpsilon:
+		return 0.0
+	case a > 1.0-coverageEpsilon:
+		return 1.0
+	default:
+		return a
+	}
+}
+
+// accumulateLine adds the signed area to the right of a line segment, clipped
+// to the image bounds, to the area buffer.  Coordinates are relative to the
+// image bounds.  The portions of the segment that lie to the left or right of
+// the bounds are projected onto the corresponding boundary, where they still
+// contribute to the winding of the pixels to their right.  Segments with a
+// non-finite endpoint are ignored.
+func (z *Rasterizer) accumulateLine(a, b Point) {
+	if !a.finite() || !b.finite() {
+		return
+	}
+
+	// Split the segment where it crosses a vertical boundary.
+	wd := float64(z.bounds.Dx())
+	for _, edge := range []float64{0.0, wd} {
+		if (a.X < edge) != (b.X < edge) && a.X != edge && b.X != edge {
+			t := (edge - a.X) / (b.X - a.X)
+			m := Point{X: edge, Y: a.Y + t*(b.Y-a.Y)}
+			z.accumulateLine(a, m)
+			z.accumulateLine(m, b)
+			return
+		}
+	}
+	a.X = math.Max(0.0, math.Min(a.X, wd))
+	b.X = math.Max(0.0, math.Min(b.X, wd))
+
+	// Orient the segment downward.
+	if a.Y == b.Y {
+		return
+	}
+	dir := float32(1.0)
+	if a.Y > b.Y {
+		a, b = b, a
+		dir = -1.0
+	}
+	ht := float64(z.bounds.Dy())
+	if a.Y >= ht {
+		return
+	}
+
+	// Accumulate the signed area in each row the segment crosses.  This
+	// is the technique used by font-rs and golang.org/x/image/vector.
+	stride := z.bounds.Dx() + 2
+	dxdy := (b.X - a.X) / (b.Y - a.Y)
+	x := a.X
+	y0 := int(math.Floor(a.Y))
+	if a.Y < 0.0 {
+		x -= a.Y * dxdy
+		y0 = 0
+	}
+	y1 := int(math.Min(math.Ceil(b.Y), ht))
+	for y := y0; y < y1; y++ {
+		row := z.area[y*stride : (y+1)*stride]
+		dy := math.Min(float64(y+1), b.Y) - math.Max(float64(y), a.Y)
+		xNext := math.Max(0.0, math.Min(x+dxdy*dy, wd))
+		d := float32(dy) * dir
+		x0, x1 := x, xNext
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+		x0Floor := math.Floor(x0)
+		x0i := int(x0Floor)
+		x1Ceil := math.Ceil(x1)
+		x1i := int(x1Ceil)
+		z.touch(y, x0i, z.bounds.Dx()-1)
+		if x1i <= x0i+1 {
+			xmf := float32(0.5*(x+xNext) - x0Floor)
+			row[x0i] += d - d*xmf
+			row[x0i+1] += d * xmf
+		} else {
+			s := float32(1.0 / (x1 - x0))
+			x0f := float32(x0 - x0Floor)
+			oneMinusX0f := 1.0 - x0f
+			a0 := 0.5 * s * oneMinusX0f * oneMinusX0f
+			x1f := float32(x1 - x1Ceil + 1.0)
+			am := 0.5 * s * x1f * x1f
+			row[x0i] += d * a0
+			if x1i == x0i+2 {
+				row[x0i+1] += d * (1.0 - a0 - am)
+			} else {
+				a1 := s * (1.5 - x0f)
+				row[x0i+1] += d * (a1 - a0)
+				for xi := x0i + 2; xi < x1i-1; xi++ {
+					row[xi] += d * s
+				}
+				a2 := a1 + float32(x1i-x0i-3)*s
+				row[x1i-1] += d * (1.0 - a2 - am)
+			}
+			row[x1i] += d * am
+		}
+		x = xNext
+	}
+}
+
+// Fill accumulates a given color onto every pixel enclosed by a Path, weighted
+// by the fraction of the pixel that the Path covers.  Every subpath is
+// implicitly closed.  A fill rule determines which regions the Path
+// encloses.  Unlike compositing, which would make the overlap of two
+// translucent shapes more opaque than either shape, accumulating averages the
+// colors of overlapping shapes.
+func (z *Rasterizer) Fill(p *Path, rule FillRule, c color.Color) {
+	wd, ht := z.bounds.Dx(), z.bounds.Dy()
+	if z.area == nil {
+		z.area = make([]float32, (wd+2)*ht)
+	}
+
+	// Accumulate the signed area of every edge.
+	off := Point{X: float64(z.bounds.Min.X), Y: float64(z.bounds.Min.Y)}
+	rel := func(pt Point) Point {
+		return Point{X: pt.X - off.X, Y: pt.Y - off.Y}
+	}
+	for _, sp := range p.subpaths {
+		for i := range sp {
+			j := (i + 1) % len(sp)
+			z.accumulateLine(rel(sp[i]), rel(sp[j]))
+		}
+	}
+
+	// Convert the area to coverage, clearing the area buffer in the
+	// process.
+	stride := wd + 2
+	for y := range z.rowMin {
+		if z.rowMin[y] > z.rowMax[y] {
+			continue
+		}
+		row := z.area[y*stride : (y+1)*stride]
+		acc := float32(0.0)
+		for x := z.rowMin[y]; x < stride; x++ {
+			acc += row[x]
+			row[x] = 0.0
+			if x < wd {
+				z.cov[y*wd+x] = float32(rule.coverage(float64(acc)))
+			}
+		}
+	}
+	z.flush(c)
+}
@@ -0,0 +1,172 @@
+// This file defines a suite of tests for filling paths.
+
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/spakin/accumimage/v2"
+)
+
+// rectPath returns a Path that traces a rectangle, clockwise or
+// counterclockwise.
+func rectPath(p *Path, x0, y0, x1, y1 float64, ccw bool) {
+	p.MoveTo(Pt(x0, y0))
+	if ccw {
+		p.LineTo(Pt(x0, y1))
+		p.LineTo(Pt(x1, y1))
+		p.LineTo(Pt(x1, y0))
+	} else {
+		p.LineTo(Pt(x1, y0))
+		p.LineTo(Pt(x1, y1))
+		p.LineTo(Pt(x0, y1))
+	}
+	p.Close()
+}
+
+// TestFillRect fills a rectangle with fractional bounds and confirms that
+// each pixel's tally equals the fraction of the pixel the rectangle covers.
+func TestFillRect(t *testing.T) {
+	img := accumimage.NewWeightedNRGBA(image.Rect(0, 0, 6, 5))
+	var p Path
+	rectPath(&p, 1.25, 0.5, 4.5, 3.0, false)
+	NewRasterizer(img).Fill(&p, NonZero, color.White)
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 6; x++ {
+			// Compute the exact area of overlap.
+			ox := math.Max(0.0, math.Min(float64(x+1), 4.5)-math.Max(float64(x), 1.25))
+			oy := math.Max(0.0, math.Min(float64(y+1), 3.0)-math.Max(float64(y), 0.5))
+			e := ox * oy
+			if tally := img.WeightedNRGBAAt(x, y).Tally; math.Abs(tally-e) > 1e-4 {
+				t.Fatalf("expected a tally of %v but saw %v at (%d, %d)", e, tally, x, y)
+			}
+		}
+	}
+}
+
+// TestFillRules fills two overlapping squares with both fill rules and both
+// relative orientations and confirms that the overlap is filled or left empty
+// as appropriate.
+func TestFillRules(t *testing.T) {
+	for _, tc := range []struct {
+		rule   FillRule
+		ccw    bool
+		filled bool
+	}{
+		{NonZero, false, true},
+		{NonZero, true, false},
+		{EvenOdd, false, false},
+		{EvenOdd, true, false},
+	} {
+		img := accumimage.NewWeightedNRGBA(image.Rect(0, 0, 10, 10))
+		var p Path
+		rectPath(&p, 1.0, 1.0, 6.0, 6.0, false)
+		rectPath(&p, 4.0, 4.0, 9.0, 9.0, tc.ccw)
+		NewRasterizer(img).Fill(&p, tc.rule, color.White)
+		for pt, e := range map[image.Point]bool{
+			{2, 2}: true,
+			{7, 7}: true,
+			{4, 4}: tc.filled,
+			{5, 5}: tc.filled,
+			{2, 7}: false,
+		} {
+			tally := img.WeightedNRGBAAt(pt.X, pt.Y).Tally
+			if (tally == 1.0) != e || (tally != 0.0 && tally != 1.0) {
+				t.Fatalf("rule %d, ccw %v: saw unexpected tally %v at %v", tc.rule, tc.ccw, tally, pt)
+			}
+		}
+	}
+}
+
+// TestFillClipped fills a triangle that extends beyond the image bounds on
+// every side and confirms that the visible portion is filled.
+func TestFillClipped(t *testing.T) {
+	img := accumimage.NewWeightedLabA(image.Rect(5, 5, 15, 15))
+	var p Path
+	p.MoveTo(Pt(10.0, -20.0))
+	p.LineTo(Pt(40.0, 30.0))
+	p.LineTo(Pt(-20.0, 30.0))
+	NewRasterizer(img).Fill(&p, EvenOdd, color.White)
+	for y := 5; y < 15; y++ {
+		for x := 5; x < 15; x++ {
+			if tally := img.WeightedLabAAt(x, y).Tally; tally != 1.0 {
+				t.Fatalf("expected a tally of 1 but saw %v at (%d, %d)", tally, x, y)
+			}
+		}
+	}
+}
+
+// TestFillCircle fills a circle built from cubic Bézier curves and confirms
+// that the total coverage approximates the circle's area.
+func TestFillCircle(t *testing.T) {
+	img := accumimage.NewWeightedNRGBA(image.Rect(0, 0, 40, 40))
+	const cx, cy, r = 20.3, 19.6, 15.0
+	const k = 0.5522847498 * r // Control-point distance for a circular arc
+	var p Path
+	p.MoveTo(Pt(cx+r, cy))
+	p.CubeTo(Pt(cx+r, cy+k), Pt(cx+k, cy+r), Pt(cx, cy+r))
+	p.CubeTo(Pt(cx-k, cy+r), Pt(cx-r, cy+k), Pt(cx-r, cy))
+	p.CubeTo(Pt(cx-r, cy-k), Pt(cx-k, cy-r), Pt(cx, cy-r))
+	p.CubeTo(Pt(cx+k, cy-r), Pt(cx+r, cy-k), Pt(cx+r, cy))
+	p.Close()
+	NewRasterizer(img).Fill(&p, NonZero, color.White)
+	total := 0.0
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			total += img.WeightedNRGBAAt(x, y).Tally
+		}
+	}
+	// Flattening the curves into chords loses a sliver of area along the
+	// perimeter.
+	if e := math.Pi * r * r; math.Abs(total-e) > 2.0*math.Pi*r*flatness {
+		t.Fatalf("expected a total tally of %.2f but saw %.2f", e, total)
+	}
+}
+
+// TestFillBlend fills two overlapping translucent polygons onto an NRGBA image
+// and confirms that the overlap averages the colors instead of increasing
+// opacity.
+func TestFillBlend(t *testing.T) {
+	img := accumimage.NewNRGBA(image.Rect(0, 0, 8, 4))
+	z := NewRasterizer(img)
+	var p, q Path
+	rectPath(&p, 0.0, 0.0, 5.0, 4.0, false)
+	rectPath(&q, 3.0, 0.0, 8.0, 4.0, true)
+	z.Fill(&p, NonZero, color.NRGBA{R: 200, A: 128})
+	z.Fill(&q, EvenOdd, color.NRGBA{B: 100, A: 128})
+	for x, e := range []color.NRGBA{
+		{R: 200, A: 128},
+		{R: 100, B: 50, A: 128},
+		{B: 100, A: 128},
+	} {
+		if c := img.ColorNRGBAAt([]int{1, 4, 6}[x], 2); c != e {
+			t.Fatalf("expected %v but saw %v", e, c)
+		}
+	}
+	if c := img.NRGBAAt(4, 2); c.Tally != 2*CoverageLevels {
+		t.Fatalf("expected a tally of %d but saw %d", 2*CoverageLevels, c.Tally)
+	}
+}
+
+// TestStrokePath strokes a closed Path and confirms that the closing segment
+// is drawn.
+func TestStrokePath(t *testing.T) {
+	img := accumimage.NewWeightedNRGBA(image.Rect(0, 0, 10, 10))
+	var p Path
+	p.MoveTo(Pt(1.5, 1.5))
+	p.LineTo(Pt(8.5, 1.5))
+	p.LineTo(Pt(8.5, 8.5))
+	p.Close()
+	NewRasterizer(img).Stroke(&p, color.White)
+	for _, pt := range []image.Point{{5, 1}, {8, 5}, {5, 5}, {1, 1}, {8, 8}} {
+		if tally := img.WeightedNRGBAAt(pt.X, pt.Y).Tally; tally != 1.0 {
+			t.Fatalf("expected a tally of 1 but saw %v at %v", tally, pt)
+		}
+	}
+	if tally := img.WeightedNRGBAAt(1, 8).Tally; tally != 0.0 {
+		t.Fatalf("expected a tally of 0 but saw %v", tally)
+	}
+}
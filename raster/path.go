@@ -0,0 +1,83 @@
+// This file defines the Path type, which describes shapes to be filled or
+// stroked.
+
+package raster
+
+import "image/color"
+
+// A Path is a sequence of subpaths, each of which is a sequence of connected
+// line segments and Bézier curves.  Curves are approximated by line segments
+// as they are added to a Path.  The zero value is an empty Path, ready to
+// use.
+type Path struct {
+	subpaths [][]Point // Vertices of each subpath
+	closed   []bool    // Whether each subpath is closed
+}
+
+// current returns the index of the subpath to which a segment should be
+// appended, starting a new subpath if necessary.  A new subpath begins at the
+// start of the previous subpath if that subpath is closed or at the origin if
+// there is no previous subpath.
+func (p *Path) current() int {
+	n := len(p.subpaths)
+	switch {
+	case n == 0:
+		p.MoveTo(Point{})
+	case p.closed[n-1]:
+		p.MoveTo(p.subpaths[n-1][0])
+	}
+	return len(p.subpaths) - 1
+}
+
+// MoveTo begins a new subpath at a given point.
+func (p *Path) MoveTo(pt Point) {
+	p.subpaths = append(p.subpaths, []Point{pt})
+	p.closed = append(p.closed, false)
+}
+
+// LineTo adds a line segment from the current point to a given point.
+func (p *Path) LineTo(pt Point) {
+	i := p.current()
+	p.subpaths[i] = append(p.subpaths[i], pt)
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to a given
+// point, with a given control point.
+func (p *Path) QuadTo(ctrl, pt Point) {
+	i := p.current()
+	sp := p.subpaths[i]
+	p.subpaths[i] = append(sp, flattenQuad(sp[len(sp)-1], ctrl, pt)[1:]...)
+}
+
+// CubeTo adds a cubic Bézier curve from the current point to a given point,
+// with two given control points.
+func (p *Path) CubeTo(ctrl1, ctrl2, pt Point) {
+	i := p.current()
+	sp := p.subpaths[i]
+	p.subpaths[i] = append(sp, flattenCubic(sp[len(sp)-1], ctrl1, ctrl2, pt)[1:]...)
+}
+
+// Close closes the current subpath with a line segment back to its first
+// point.  Subsequent segments begin a new subpath at that point.
+func (p *Path) Close() {
+	if n := len(p.subpaths); n > 0 {
+		p.closed[n-1] = true
+	}
+}
+
+// Stroke accumulates a given color along a Path.  Pixels that the Path
+// crosses more than once receive the color only once.
+func (z *Rasterizer) Stroke(p *Path, c color.Color) {
+	for i, sp := range p.subpaths {
+		if len(sp) == 1 {
+			z.stampSegment(sp[0], sp[0])
+		}
+		for j := 1; j < len(sp); j++ {
+			z.stampSegment(sp[j-1], sp[j])
+		}
+		if p.closed[i] {
+			z.stampSegment(sp[len(sp)-1], sp[0])
+		}
+	}
+	z.flush(c)
+}
@@ -0,0 +1,113 @@
+// This file defines the Rasterizer type and the means by which it accumulates
+// coverage-weighted colors.
+
+package raster
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/spakin/accumimage/v2"
+)
+
+// CoverageLevels is the weight with which a Rasterizer accumulates a color
+// onto a fully covered pixel of an image that supports only integer weights.
+// Partially covered pixels receive proportionally smaller weights.
+const CoverageLevels = accumimage.WeightLevels
+
+// A Point is a location in floating-point image coordinates.  Pixel (x, y)
+// spans the square from (x, y) to (x+1, y+1).
+type Point struct {
+	X, Y float64
+}
+
+// Pt is shorthand for Point{X: x, Y: y}.
+func Pt(x, y float64) Point {
+	return Point{X: x, Y: y}
+}
+
+// finite returns true if and only if both coordinates of a Point are finite.
+func (p Point) finite() bool {
+	return !math.IsNaN(p.X) && !math.IsInf(p.X, 0) &&
+		!math.IsNaN(p.Y) && !math.IsInf(p.Y, 0)
+}
+
+// A Rasterizer draws anti-aliased shapes onto an accumulating image.  A
+// Rasterizer retains scratch memory between calls, so reusing a Rasterizer
+// for many shapes is faster than creating a new Rasterizer for each shape.
+// A Rasterizer is not safe for concurrent use.
+type Rasterizer struct {
+	// Width is the width in pixels of lines and curves.
+	Width float64
+
+	dst    accumimage.WeightedAccumImage // Image onto which to accumulate colors
+	bounds image.Rectangle               // Bounds of dst
+	cov    []float32                     // Per-pixel coverage of the current shape
+	area   []float32                     // Per-pixel signed area for filling shapes
+	rowMin []int                         // Per-row minimum x offset that was touched
+	rowMax []int                         // Per-row maximum x offset that was touched
+}
+
+// NewRasterizer returns a Rasterizer that draws onto a given image with a
+// line width of 1.  The Rasterizer accumulates colors onto dst through
+// accumimage.Weighted, so images whose pixels maintain fractional tallies
+// receive each pixel's coverage as its weight, and images whose pixels
+// maintain integer tallies receive the coverage multiplied by CoverageLevels.
+// NewRasterizer panics if accumimage.Weighted does not support dst.
+func NewRasterizer(dst accumimage.AccumImage) *Rasterizer {
+	wdst := accumimage.Weighted(dst)
+	if wdst == nil {
+		panic(fmt.Sprintf("raster: cannot accumulate weighted colors onto a %T", dst))
+	}
+	b := dst.Bounds()
+	z := &Rasterizer{
+		Width:  1.0,
+		dst:    wdst,
+		bounds: b,
+		cov:    make([]float32, b.Dx()*b.Dy()),
+		rowMin: make([]int, b.Dy()),
+		rowMax: make([]int, b.Dy()),
+	}
+	z.resetRows()
+	return z
+}
+
+// resetRows marks every row as untouched.
+func (z *Rasterizer) resetRows() {
+	for i := range z.rowMin {
+		z.rowMin[i] = math.MaxInt
+		z.rowMax[i] = math.MinInt
+	}
+}
+
+// touch records that offsets x0 through x1, inclusive, of row y, relative to
+// the image bounds, may hold nonzero coverage.
+func (z *Rasterizer) touch(y, x0, x1 int) {
+	if x0 < z.rowMin[y] {
+		z.rowMin[y] = x0
+	}
+	if x1 > z.rowMax[y] {
+		z.rowMax[y] = x1
+	}
+}
+
+// flush accumulates a given color onto every pixel with nonzero coverage,
+// weighted by that coverage, and clears the coverage buffer.
+func (z *Rasterizer) flush(c color.Color) {
+	c = z.dst.ColorModel().Convert(c) // Convert once, not at every pixel.
+	wd := z.bounds.Dx()
+	for j := range z.rowMin {
+		for i := z.rowMin[j]; i <= z.rowMax[j]; i++ {
+			k := j*wd + i
+			cov := float64(z.cov[k])
+			if cov <= 0.0 {
+				continue
+			}
+			z.cov[k] = 0.0
+			z.dst.AddWeighted(z.bounds.Min.X+i, z.bounds.Min.Y+j, c, math.Min(cov, 1.0))
+		}
+	}
+	z.resetRows()
+}
@@ -0,0 +1,160 @@
+// This file defines methods for stroking lines and curves.
+
+package raster
+
+import (
+	"image/color"
+	"math"
+)
+
+// flatness is the maximum distance in pixels between a Bézier curve and the
+// polyline used to approximate it.
+const flatness = 0.1
+
+// distance returns the distance from point p to the line segment from a to b.
+func distance(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	t := 0.0
+	if l2 := dx*dx + dy*dy; l2 > 0.0 {
+		t = ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / l2
+		t = math.Max(0.0, math.Min(t, 1.0))
+	}
+	return math.Hypot(p.X-(a.X+t*dx), p.Y-(a.Y+t*dy))
+}
+
+// coverage estimates the fraction of a pixel covered by a line of a given
+// width whose center line lies at a given distance from the pixel's center.
+func coverage(width, d float64) float64 {
+	if width < 1.0 {
+		return width * math.Max(1.0-d, 0.0)
+	}
+	return math.Max(0.0, math.Min(width/2.0+0.5-d, 1.0))
+}
+
+// stampSegment records the coverage of a line segment from a to b, with round
+// caps, in the coverage buffer.  Each pixel retains the maximum of its
+// previous coverage and the segment's coverage.  Segments with a non-finite
+// endpoint are ignored.
+func (z *Rasterizer) stampSegment(a, b Point) {
+	if !a.finite() || !b.finite() {
+		return
+	}
+	wd, ht := z.bounds.Dx(), z.bounds.Dy()
+	ext := math.Max(z.Width, 1.0)/2.0 + 0.5 // Reach of nonzero coverage
+	ox, oy := float64(z.bounds.Min.X), float64(z.bounds.Min.Y)
+	ymin, ymax := math.Min(a.Y, b.Y)-oy, math.Max(a.Y, b.Y)-oy
+	j0 := int(math.Min(math.Max(math.Ceil(ymin-ext-0.5), 0.0), float64(ht)))
+	j1 := int(math.Max(math.Min(math.Floor(ymax+ext-0.5), float64(ht-1)), -1.0))
+	dx, dy := b.X-a.X, b.Y-a.Y
+	for j := j0; j <= j1; j++ {
+		// Determine the portion of the segment within reach of the
+		// row's pixel centers.
+		yc := oy + float64(j) + 0.5
+		lo, hi := 0.0, 1.0
+		if dy != 0.0 {
+			t0, t1 := (yc-ext-a.Y)/dy, (yc+ext-a.Y)/dy
+			if t0 > t1 {
+				t0, t1 = t1, t0
+			}
+			lo, hi = math.Max(lo, t0), math.Min(hi, t1)
+			if lo > hi {
+				continue
+			}
+		}
+		xa, xb := a.X+lo*dx-ox, a.X+hi*dx-ox
+		i0 := int(math.Min(math.Max(math.Ceil(math.Min(xa, xb)-ext-0.5), 0.0), float64(wd)))
+		i1 := int(math.Max(math.Min(math.Floor(math.Max(xa, xb)+ext-0.5), float64(wd-1)), -1.0))
+		if i0 > i1 {
+			continue
+		}
+
+		// Record the coverage of each pixel in the row.
+		z.touch(j, i0, i1)
+		for i := i0; i <= i1; i++ {
+			p := Point{X: ox + float64(i) + 0.5, Y: yc}
+			cov := float32(coverage(z.Width, distance(p, a, b)))
+			if k := j*wd + i; cov > z.cov[k] {
+				z.cov[k] = cov
+			}
+		}
+	}
+}
+
+// Line accumulates a given color along the line segment from p0 to p1.
+func (z *Rasterizer) Line(p0, p1 Point, c color.Color) {
+	z.stampSegment(p0, p1)
+	z.flush(c)
+}
+
+// Polyline accumulates a given color along a sequence of connected line
+// segments.  A single point is drawn as a dot.
+func (z *Rasterizer) Polyline(pts []Point, c color.Color) {
+	switch len(pts) {
+	case 0:
+		return
+	case 1:
+		z.stampSegment(pts[0], pts[0])
+	default:
+		for i := 1; i < len(pts); i++ {
+			z.stampSegment(pts[i-1], pts[i])
+		}
+	}
+	z.flush(c)
+}
+
+// segments returns the number of equal-parameter line segments needed to
+// approximate a curve to within flatness given a bound on the magnitude of
+// the curve's second derivative.
+func segments(dd float64) int {
+	n := int(math.Ceil(math.Sqrt(dd / (8.0 * flatness))))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// flattenQuad approximates a quadratic Bézier curve with a polyline.
+func flattenQuad(p0, p1, p2 Point) []Point {
+	dd := 2.0 * math.Hypot(p0.X-2.0*p1.X+p2.X, p0.Y-2.0*p1.Y+p2.Y)
+	n := segments(dd)
+	pts := make([]Point, n+1)
+	for i := range pts {
+		t := float64(i) / float64(n)
+		u := 1.0 - t
+		pts[i] = Point{
+			X: u*u*p0.X + 2.0*u*t*p1.X + t*t*p2.X,
+			Y: u*u*p0.Y + 2.0*u*t*p1.Y + t*t*p2.Y,
+		}
+	}
+	return pts
+}
+
+// flattenCubic approximates a cubic Bézier curve with a polyline.
+func flattenCubic(p0, p1, p2, p3 Point) []Point {
+	dd := 6.0 * math.Max(
+		math.Hypot(p0.X-2.0*p1.X+p2.X, p0.Y-2.0*p1.Y+p2.Y),
+		math.Hypot(p1.X-2.0*p2.X+p3.X, p1.Y-2.0*p2.Y+p3.Y))
+	n := segments(dd)
+	pts := make([]Point, n+1)
+	for i := range pts {
+		t := float64(i) / float64(n)
+		u := 1.0 - t
+		pts[i] = Point{
+			X: u*u*u*p0.X + 3.0*u*u*t*p1.X + 3.0*u*t*t*p2.X + t*t*t*p3.X,
+			Y: u*u*u*p0.Y + 3.0*u*u*t*p1.Y + 3.0*u*t*t*p2.Y + t*t*t*p3.Y,
+		}
+	}
+	return pts
+}
+
+// QuadBezier accumulates a given color along the quadratic Bézier curve from
+// p0 to p2 with control point p1.
+func (z *Rasterizer) QuadBezier(p0, p1, p2 Point, c color.Color) {
+	z.Polyline(flattenQuad(p0, p1, p2), c)
+}
+
+// CubicBezier accumulates a given color along the cubic Bézier curve from p0
+// to p3 with control points p1 and p2.
+func (z *Rasterizer) CubicBezier(p0, p1, p2, p3 Point, c color.Color) {
+	z.Polyline(flattenCubic(p0, p1, p2, p3), c)
+}
@@ -0,0 +1,237 @@
+// This file defines a suite of tests for stroking lines and curves.
+
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/spakin/accumimage/v2"
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// TestLine draws a horizontal line and confirms that it covers exactly one
+// row of pixels, with round caps at either end.
+func TestLine(t *testing.T) {
+	img := accumimage.NewWeightedNRGBA(image.Rect(0, 0, 10, 5))
+	z := NewRasterizer(img)
+	red := color.NRGBA{R: 255, A: 255}
+	z.Line(Pt(1.0, 2.5), Pt(8.0, 2.5), red)
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 10; x++ {
+			var e float64
+			switch {
+			case y != 2:
+			case x >= 1 && x <= 7:
+				e = 1.0
+			case x == 0 || x == 8:
+				e = 0.5
+			}
+			if tally := img.WeightedNRGBAAt(x, y).Tally; math.Abs(tally-e) > 1e-6 {
+				t.Fatalf("expected a tally of %v but saw %v at (%d, %d)", e, tally, x, y)
+			}
+		}
+	}
+	if c := img.ColorNRGBAAt(4, 2); c != red {
+		t.Fatalf("expected %v but saw %v", red, c)
+	}
+}
+
+// TestLineClipped draws a thick line that extends beyond the image bounds and
+// confirms that only the visible portion is drawn.
+func TestLineClipped(t *testing.T) {
+	img := accumimage.NewWeightedLabA(image.Rect(10, 10, 20, 20))
+	z := NewRasterizer(img)
+	z.Width = 3.0
+	z.Line(Pt(-5.0, 15.0), Pt(40.0, 15.0), color.White)
+	for x := 10; x < 20; x++ {
+		for y, e := range map[int]float64{12: 0.0, 13: 0.5, 14: 1.0, 15: 1.0, 16: 0.5, 17: 0.0} {
+			if tally := img.WeightedLabAAt(x, y).Tally; math.Abs(tally-e) > 1e-6 {
+				t.Fatalf("expected a tally of %v but saw %v at (%d, %d)", e, tally, x, y)
+			}
+		}
+	}
+}
+
+// TestLineBlend draws two crossing lines onto an NRGBA image and confirms
+// that the pixel at which they cross averages their colors.
+func TestLineBlend(t *testing.T) {
+	img := accumimage.NewNRGBA(image.Rect(0, 0, 9, 9))
+	z := NewRasterizer(img)
+	z.Line(Pt(0.0, 4.5), Pt(9.0, 4.5), color.NRGBA{R: 200, A: 255})
+	z.Line(Pt(4.5, 0.0), Pt(4.5, 9.0), color.NRGBA{B: 100, A: 255})
+	if c := img.NRGBAAt(4, 4); c.Tally != 2*CoverageLevels {
+		t.Fatalf("expected a tally of %d but saw %d", 2*CoverageLevels, c.Tally)
+	}
+	e := color.NRGBA{R: 100, B: 50, A: 255}
+	if c := img.ColorNRGBAAt(4, 4); c != e {
+		t.Fatalf("expected %v but saw %v", e, c)
+	}
+	if c := img.NRGBAAt(0, 4); c.Tally != CoverageLevels {
+		t.Fatalf("expected a tally of %d but saw %d", CoverageLevels, c.Tally)
+	}
+}
+
+// TestLineGeneric draws a line onto image types that the Rasterizer does not
+// handle specially and confirms that partial coverage reduces each pixel's
+// weight rather than its color.
+func TestLineGeneric(t *testing.T) {
+	img := accumimage.NewNRGBA64(image.Rect(0, 0, 5, 3))
+	z := NewRasterizer(img)
+	z.Line(Pt(0.0, 1.0), Pt(5.0, 1.0), color.NRGBA64{G: 0xffff, A: 0xffff})
+	for _, y := range []int{0, 1} {
+		c := img.NRGBA64At(2, y)
+		if c.Tally != (CoverageLevels+1)/2 {
+			t.Fatalf("expected a tally of %d but saw %d at (2, %d)", (CoverageLevels+1)/2, c.Tally, y)
+		}
+		if e := (color.NRGBA64{G: 0xffff, A: 0xffff}); c.NRGBA64() != e {
+			t.Fatalf("expected %v but saw %v at (2, %d)", e, c.NRGBA64(), y)
+		}
+	}
+
+	// Partially covered pixels should not darken a gray line.
+	gray := accumimage.NewGray(image.Rect(0, 0, 5, 3))
+	NewRasterizer(gray).Line(Pt(0.0, 1.3), Pt(5.0, 1.3), color.Gray{Y: 200})
+	for y := 0; y < 3; y++ {
+		if c := gray.GrayAt(2, y); c.Tally > 0 && c.Gray().Y != 200 {
+			t.Fatalf("expected a gray level of 200 but saw %d at (2, %d)", c.Gray().Y, y)
+		}
+	}
+}
+
+// TestLineImage draws a line onto generic accumimage.Images and confirms that
+// coverage weights an Image as it would the corresponding non-generic image.
+func TestLineImage(t *testing.T) {
+	r := image.Rect(0, 0, 5, 3)
+	red := color.NRGBA{R: 255, A: 255}
+	wimg := accumimage.NewImage[accumcolor.WeightedNRGBA](r)
+	NewRasterizer(wimg).Line(Pt(0.0, 1.0), Pt(5.0, 1.0), red)
+	if c := wimg.AccumAt(2, 1); math.Abs(c.Tally-0.5) > 1e-6 {
+		t.Fatalf("expected a tally of 0.5 but saw %v", c.Tally)
+	}
+	nimg := accumimage.NewImage[accumcolor.NRGBA](r)
+	NewRasterizer(nimg).Line(Pt(0.0, 1.0), Pt(5.0, 1.0), red)
+	if c := nimg.AccumAt(2, 1); c.Tally != (CoverageLevels+1)/2 {
+		t.Fatalf("expected a tally of %d but saw %d", (CoverageLevels+1)/2, c.Tally)
+	}
+}
+
+// TestLineNonFinite confirms that segments with non-finite endpoints are
+// ignored.
+func TestLineNonFinite(t *testing.T) {
+	img := accumimage.NewWeightedNRGBA(image.Rect(0, 0, 4, 4))
+	z := NewRasterizer(img)
+	nan, inf := math.NaN(), math.Inf(1)
+	z.Line(Pt(nan, 1.0), Pt(3.0, 2.0), color.White)
+	z.Line(Pt(1.0, 1.0), Pt(3.0, inf), color.White)
+	z.Line(Pt(1.0, 1e300), Pt(-1e300, 2.0), color.White)
+	var p Path
+	p.MoveTo(Pt(0.0, 0.0))
+	p.LineTo(Pt(nan, 4.0))
+	p.LineTo(Pt(4.0, inf))
+	z.Fill(&p, NonZero, color.White)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if tally := img.WeightedNRGBAAt(x, y).Tally; tally != 0.0 {
+				t.Fatalf("expected a tally of 0 but saw %v at (%d, %d)", tally, x, y)
+			}
+		}
+	}
+}
+
+// TestRasterizerUnsupported confirms that NewRasterizer rejects an image that
+// cannot accumulate weighted colors.
+func TestRasterizerUnsupported(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected NewRasterizer to panic")
+		}
+	}()
+	NewRasterizer(accumimage.NewMedianNRGBA(image.Rect(0, 0, 2, 2)))
+}
+
+// TestPolyline draws a polyline with a sharp corner and confirms that no
+// pixel is drawn more than once.
+func TestPolyline(t *testing.T) {
+	img := accumimage.NewWeightedNRGBA(image.Rect(0, 0, 12, 12))
+	z := NewRasterizer(img)
+	z.Width = 2.0
+	z.Polyline([]Point{Pt(1.0, 1.0), Pt(10.0, 6.0), Pt(1.0, 11.0)}, color.Black)
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 12; x++ {
+			if tally := img.WeightedNRGBAAt(x, y).Tally; tally > 1.0 {
+				t.Fatalf("expected a tally of at most 1 but saw %v at (%d, %d)", tally, x, y)
+			}
+		}
+	}
+	if tally := img.WeightedNRGBAAt(9, 5).Tally; tally != 1.0 {
+		t.Fatalf("expected a tally of 1 at the corner but saw %v", tally)
+	}
+}
+
+// TestQuadBezier confirms that a quadratic Bézier curve with collinear
+// control points is drawn like a line.
+func TestQuadBezier(t *testing.T) {
+	bnds := image.Rect(0, 0, 12, 6)
+	line := accumimage.NewWeightedNRGBA(bnds)
+	NewRasterizer(line).Line(Pt(1.0, 2.2), Pt(11.0, 3.7), color.White)
+	curve := accumimage.NewWeightedNRGBA(bnds)
+	NewRasterizer(curve).QuadBezier(Pt(1.0, 2.2), Pt(6.0, 2.95), Pt(11.0, 3.7), color.White)
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 12; x++ {
+			lt := line.WeightedNRGBAAt(x, y).Tally
+			ct := curve.WeightedNRGBAAt(x, y).Tally
+			if math.Abs(lt-ct) > 1e-6 {
+				t.Fatalf("expected a tally of %v but saw %v at (%d, %d)", lt, ct, x, y)
+			}
+		}
+	}
+}
+
+// TestCubicBezier draws a symmetric cubic Bézier curve and confirms that the
+// result is symmetric and passes through the curve's midpoint.
+func TestCubicBezier(t *testing.T) {
+	img := accumimage.NewWeightedNRGBA(image.Rect(0, 0, 20, 12))
+	z := NewRasterizer(img)
+	z.CubicBezier(Pt(1.0, 10.0), Pt(4.0, -2.0), Pt(16.0, -2.0), Pt(19.0, 10.0), color.White)
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 10; x++ {
+			lt := img.WeightedNRGBAAt(x, y).Tally
+			rt := img.WeightedNRGBAAt(19-x, y).Tally
+			if math.Abs(lt-rt) > 0.05 {
+				t.Fatalf("expected equal tallies but saw %v at (%d, %d) and %v at (%d, %d)", lt, x, y, rt, 19-x, y)
+			}
+		}
+	}
+
+	// The curve's midpoint is (10, 1).
+	for _, x := range []int{9, 10} {
+		if tally := img.WeightedNRGBAAt(x, 0).Tally; math.Abs(tally-0.5) > 0.1 {
+			t.Fatalf("expected a tally of about 0.5 but saw %v at (%d, 0)", tally, x)
+		}
+	}
+}
+
+// TestFlatness confirms that flattened curves lie within flatness of the
+// true curves.
+func TestFlatness(t *testing.T) {
+	p0, p1, p2, p3 := Pt(0.0, 0.0), Pt(100.0, 300.0), Pt(200.0, -300.0), Pt(300.0, 0.0)
+	pts := flattenCubic(p0, p1, p2, p3)
+	for i := 0; i <= 1000; i++ {
+		s := float64(i) / 1000.0
+		u := 1.0 - s
+		p := Point{
+			X: u*u*u*p0.X + 3.0*u*u*s*p1.X + 3.0*u*s*s*p2.X + s*s*s*p3.X,
+			Y: u*u*u*p0.Y + 3.0*u*u*s*p1.Y + 3.0*u*s*s*p2.Y + s*s*s*p3.Y,
+		}
+		d := math.Inf(1)
+		for j := 1; j < len(pts); j++ {
+			d = math.Min(d, distance(p, pts[j-1], pts[j]))
+		}
+		if d > flatness {
+			t.Fatalf("expected a distance of at most %v but saw %v", flatness, d)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+// This file defines a general-purpose, kernel-based image resampler.
+
+package accumimage
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// A Kernel is a separable filter kernel centered on zero.
+type Kernel struct {
+	// Support is the kernel radius.  At(t) is assumed to be zero for
+	// |t| > Support.
+	Support float64
+	// At is the kernel function.  It may be negative for some t, but its
+	// integral should be positive.
+	At func(t float64) float64
+	// Integral, if not nil, returns the integral of At from -Support to
+	// t.  Resample integrates At over the footprint of each source pixel
+	// and uses Integral to do so exactly.  If Integral is nil, Resample
+	// integrates At numerically instead.
+	Integral func(t float64) float64
+}
+
+// integrate returns the integral of k.At from a to b, where a <= b.
+func (k *Kernel) integrate(a, b float64) float64 {
+	a = math.Max(a, -k.Support)
+	b = math.Min(b, k.Support)
+	switch {
+	case a >= b:
+		return 0.0
+	case k.Integral != nil:
+		return k.Integral(b) - k.Integral(a)
+	}
+
+	// Apply Simpson's rule.
+	const n = 32
+	h := (b - a) / n
+	sum := k.At(a) + k.At(b)
+	for i := 1; i < n; i++ {
+		wt := 2.0
+		if i%2 == 1 {
+			wt = 4.0
+		}
+		sum += wt * k.At(a+float64(i)*h)
+	}
+	return sum * h / 3.0
+}
+
+// sinc returns sin(pi*x)/(pi*x).
+func sinc(x float64) float64 {
+	if x == 0.0 {
+		return 1.0
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+var (
+	// BoxKernel gives equal weight to everything within half a pixel.
+	// With a scale factor of at most 1, resampling with BoxKernel
+	// averages all of the source pixels that overlap each destination
+	// pixel, weighted by the area of overlap, as does Downscale.
+	BoxKernel = &Kernel{
+		Support: 0.5,
+		At: func(t float64) float64 {
+			if t >= -0.5 && t < 0.5 {
+				return 1.0
+			}
+			return 0.0
+		},
+		Integral: func(t float64) float64 {
+			return clampFloat(t+0.5, 0.0, 1.0)
+		},
+	}
+
+	// TriangleKernel, also known as a tent filter, performs bilinear
+	// interpolation.
+	TriangleKernel = &Kernel{
+		Support: 1.0,
+		At: func(t float64) float64 {
+			return math.Max(1.0-math.Abs(t), 0.0)
+		},
+		Integral: func(t float64) float64 {
+			switch {
+			case t <= -1.0:
+				return 0.0
+			case t <= 0.0:
+				return (1.0 + t) * (1.0 + t) / 2.0
+			case t <= 1.0:
+				return 1.0 - (1.0-t)*(1.0-t)/2.0
+			default:
+				return 1.0
+			}
+		},
+	}
+
+	// LanczosKernel is a three-lobed Lanczos kernel.  It is sharper than
+	// TriangleKernel and MitchellKernel but can produce ringing near
+	// edges.
+	LanczosKernel = &Kernel{
+		Support: 3.0,
+		At: func(t float64) float64 {
+			if math.Abs(t) >= 3.0 {
+				return 0.0
+			}
+			return sinc(t) * sinc(t/3.0)
+		},
+	}
+
+	// MitchellKernel is the Mitchell-Netravali cubic kernel with B = C =
+	// 1/3, which balances blurring against ringing.
+	MitchellKernel = &Kernel{
+		Support: 2.0,
+		At: func(t float64) float64 {
+			const b, c = 1.0 / 3.0, 1.0 / 3.0
+			t = math.Abs(t)
+			switch {
+			case t < 1.0:
+				return ((12.0-9.0*b-6.0*c)*t*t*t +
+					(-18.0+12.0*b+6.0*c)*t*t +
+					(6.0 - 2.0*b)) / 6.0
+			case t < 2.0:
+				return ((-b-6.0*c)*t*t*t +
+					(6.0*b+30.0*c)*t*t +
+					(-12.0*b-48.0*c)*t +
+					(8.0*b + 24.0*c)) / 6.0
+			default:
+				return 0.0
+			}
+		},
+	}
+)
+
+// GaussianKernel returns a Gaussian kernel with a given standard deviation,
+// truncated at three standard deviations.  It panics if sigma is not
+// positive.
+func GaussianKernel(sigma float64) *Kernel {
+	if !(sigma > 0.0) {
+		panic("accumimage: GaussianKernel sigma is not positive")
+	}
+	return &Kernel{
+		Support: 3.0 * sigma,
+		At: func(t float64) float64 {
+			if math.Abs(t) > 3.0*sigma {
+				return 0.0
+			}
+			return math.Exp(-t * t / (2.0 * sigma * sigma))
+		},
+	}
+}
+
+// A Transform maps source-image coordinates to destination-image coordinates.
+// The point (x, y) in the source image maps to (x*ScaleX + OffsetX,
+// y*ScaleY + OffsetY) in the destination image.  Scale factors must be
+// positive but need not be integers.
+type Transform struct {
+	ScaleX, ScaleY   float64
+	OffsetX, OffsetY float64
+}
+
+// FitTransform returns the Transform that maps the rectangle src onto the
+// rectangle dst.
+func FitTransform(src, dst image.Rectangle) Transform {
+	sx := float64(dst.Dx()) / float64(src.Dx())
+	sy := float64(dst.Dy()) / float64(src.Dy())
+	return Transform{
+		ScaleX:  sx,
+		ScaleY:  sy,
+		OffsetX: float64(dst.Min.X) - float64(src.Min.X)*sx,
+		OffsetY: float64(dst.Min.Y) - float64(src.Min.Y)*sy,
+	}
+}
+
+// A WeightedAccumImage is an AccumImage that can additionally accumulate
+// colors with real-valued weights.
+type WeightedAccumImage interface {
+	AccumImage
+	AddWeighted(x, y int, c color.Color, w float64)
+}
+
+// A weightedSpan represents a destination pixel along one axis and the weight
+// with which a source pixel contributes to it.
+type weightedSpan struct {
+	Offset int     // Destination pixel offset from the minimum bound
+	Weight float64 // Kernel weight
+}
+
+// kernelSpans maps each of n source pixels along one axis, starting at
+// coordinate srcMin, to the destination pixels in [dstMin, dstMax) whose
+// kernels overlap the source pixel's footprint.  Each weight is the integral
+// of the kernel, centered on the destination pixel, over the footprint,
+// divided by the footprint's width so that a source pixel's weights sum to
+// about 1.  The kernel is widened by the scale factor when upscaling so that
+// the kernels of adjacent destination pixels span at least one source pixel.
+func kernelSpans(k *Kernel, srcMin, n int, scale, offset float64, dstMin, dstMax int) [][]weightedSpan {
+	width := math.Max(scale, 1.0)
+	radius := k.Support * width
+	spans := make([][]weightedSpan, n)
+	for i := range spans {
+		a := float64(srcMin+i)*scale + offset
+		b := a + scale
+		lo := int(math.Ceil(a - radius - 0.5))
+		if lo < dstMin {
+			lo = dstMin
+		}
+		hi := int(math.Floor(b + radius - 0.5))
+		if hi >= dstMax {
+			hi = dstMax - 1
+		}
+		for j := lo; j <= hi; j++ {
+			c := float64(j) + 0.5
+			w := k.integrate((a-c)/width, (b-c)/width) / scale
+			if w != 0.0 {
+				spans[i] = append(spans[i], weightedSpan{Offset: j - dstMin, Weight: w})
+			}
+		}
+	}
+	return spans
+}
+
+// resampleSpace describes how to reduce colors to four channels that can be
+// summed and how to accumulate the average of those channels.
+type resampleSpace struct {
+	toChannels func(c color.RGBA64) [4]float64
+	add        func(x, y int, ch [4]float64, w float64)
+}
+
+// clampFloat clamps a value to the range [lo, hi].
+func clampFloat(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(v, hi))
+}
+
+// labaChannels converts a color to CIE L*a*b* and alpha channels.
+func labaChannels(c color.RGBA64) [4]float64 {
+	clr := accumcolor.WeightedLabAModel.Convert(c).(accumcolor.WeightedLabA)
+	return [4]float64{clr.L, clr.A, clr.B, clr.Alpha}
+}
+
+// newResampleSpace returns a resampleSpace that accumulates onto a given
+// image.  It averages colors in CIE L*a*b* space if dst is a *WeightedLabA or
+// a *LabA and in non-alpha-premultiplied RGBA space otherwise.  It panics if
+// Weighted does not support dst.
+func newResampleSpace(dst AccumImage) resampleSpace {
+	switch dst := dst.(type) {
+	case *WeightedLabA:
+		return resampleSpace{
+			toChannels: labaChannels,
+			add: func(x, y int, ch [4]float64, w float64) {
+				dst.AddWeightedLabA(x, y, accumcolor.WeightedLabA{
+					L:     clampFloat(ch[0], 0.0, 1.0) * w,
+					A:     clampFloat(ch[1], -1.0, 1.0) * w,
+					B:     clampFloat(ch[2], -1.0, 1.0) * w,
+					Alpha: clampFloat(ch[3], 0.0, 255.0) * w,
+					Tally: w,
+				})
+			},
+		}
+	case *WeightedNRGBA:
+		return resampleSpace{
+			toChannels: nrgbaChannels,
+			add: func(x, y int, ch [4]float64, w float64) {
+				dst.AddWeightedNRGBA(x, y, accumcolor.WeightedNRGBA{
+					R:     clampFloat(ch[0], 0.0, 255.0) * w,
+					G:     clampFloat(ch[1], 0.0, 255.0) * w,
+					B:     clampFloat(ch[2], 0.0, 255.0) * w,
+					A:     clampFloat(ch[3], 0.0, 255.0) * w,
+					Tally: w,
+				})
+			},
+		}
+	case *LabA:
+		return resampleSpace{
+			toChannels: labaChannels,
+			add: func(x, y int, ch [4]float64, w float64) {
+				n := math.Round(w * WeightLevels)
+				if n <= 0.0 {
+					return
+				}
+				dst.AddLabA(x, y, accumcolor.LabA{
+					L:     clampFloat(ch[0], 0.0, 1.0) * n,
+					A:     clampFloat(ch[1], -1.0, 1.0) * n,
+					B:     clampFloat(ch[2], -1.0, 1.0) * n,
+					Alpha: uint64(clampFloat(ch[3], 0.0, 255.0)*n + 0.5),
+					Tally: uint64(n),
+				})
+			},
+		}
+	case *NRGBA:
+		return resampleSpace{
+			toChannels: nrgbaChannels,
+			add: func(x, y int, ch [4]float64, w float64) {
+				n := math.Round(w * WeightLevels)
+				if n <= 0.0 {
+					return
+				}
+				dst.AddNRGBA(x, y, accumcolor.NRGBA{
+					R:     uint64(clampFloat(ch[0], 0.0, 255.0)*n + 0.5),
+					G:     uint64(clampFloat(ch[1], 0.0, 255.0)*n + 0.5),
+					B:     uint64(clampFloat(ch[2], 0.0, 255.0)*n + 0.5),
+					A:     uint64(clampFloat(ch[3], 0.0, 255.0)*n + 0.5),
+					Tally: uint64(n),
+				})
+			},
+		}
+	}
+	wdst := Weighted(dst)
+	if wdst == nil {
+		panic(fmt.Sprintf("accumimage: Resample cannot accumulate weighted colors onto a %T", dst))
+	}
+	return resampleSpace{
+		toChannels: nrgbaChannels,
+		add: func(x, y int, ch [4]float64, w float64) {
+			c := color.NRGBA64{
+				R: uint16(clampFloat(ch[0], 0.0, 255.0)*257.0 + 0.5),
+				G: uint16(clampFloat(ch[1], 0.0, 255.0)*257.0 + 0.5),
+				B: uint16(clampFloat(ch[2], 0.0, 255.0)*257.0 + 0.5),
+				A: uint16(clampFloat(ch[3], 0.0, 255.0)*257.0 + 0.5),
+			}
+			wdst.AddWeighted(x, y, c, w)
+		},
+	}
+}
+
+// nrgbaChannels converts a color to non-alpha-premultiplied channels in the
+// range [0, 255].
+func nrgbaChannels(c color.RGBA64) [4]float64 {
+	clr := accumcolor.WeightedNRGBAModel.Convert(c).(accumcolor.WeightedNRGBA)
+	return [4]float64{clr.R, clr.G, clr.B, clr.A}
+}
+
+// Resample splats each pixel of src onto dst through a given kernel, after
+// mapping it to dst's coordinates with a given Transform.  Each pixel of dst
+// receives the average of the source colors whose footprints overlap its
+// kernel, weighted by the integral of the kernel over each footprint, and
+// accumulates that average with a weight equal to the total kernel weight.
+// Resample thereby supports both upscaling and downscaling by arbitrary
+// factors.  Pixels of dst that no source pixel covers are left untouched, so
+// their tallies reveal a lack of coverage.  Colors are averaged in CIE
+// L*a*b* space if dst is a *WeightedLabA or a *LabA and in
+// non-alpha-premultiplied RGBA space otherwise.  If dst maintains integer
+// tallies, each weight is multiplied by WeightLevels and rounded to the
+// nearest integer, as in Weighted.  Resample panics if Weighted does not
+// support dst.
+func Resample(dst AccumImage, src image.Image, tr Transform, k *Kernel) {
+	sb, db := src.Bounds(), dst.Bounds()
+	if sb.Empty() || db.Empty() {
+		return
+	}
+	xs := kernelSpans(k, sb.Min.X, sb.Dx(), tr.ScaleX, tr.OffsetX, db.Min.X, db.Max.X)
+	ys := kernelSpans(k, sb.Min.Y, sb.Dy(), tr.ScaleY, tr.OffsetY, db.Min.Y, db.Max.Y)
+
+	// Sum the weighted channels of each source pixel into a buffer.  The
+	// buffer can temporarily hold values that are out of range because
+	// kernels can have negative lobes.
+	space := newResampleSpace(dst)
+	wd := db.Dx()
+	sums := make([][4]float64, wd*db.Dy())
+	weights := make([]float64, wd*db.Dy())
+	forEachRGBA64(sb, src, sb.Min, func(x, y int, c color.RGBA64) {
+		ch := space.toChannels(c)
+		for _, sy := range ys[y-sb.Min.Y] {
+			for _, sx := range xs[x-sb.Min.X] {
+				i := sy.Offset*wd + sx.Offset
+				w := sx.Weight * sy.Weight
+				for j := range ch {
+					sums[i][j] += ch[j] * w
+				}
+				weights[i] += w
+			}
+		}
+	})
+
+	// Accumulate the average color of each covered destination pixel.
+	for i, w := range weights {
+		if w <= 0.0 {
+			continue
+		}
+		var avg [4]float64
+		for j := range avg {
+			avg[j] = sums[i][j] / w
+		}
+		space.add(db.Min.X+i%wd, db.Min.Y+i/wd, avg, w)
+	}
+}
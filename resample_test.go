@@ -0,0 +1,209 @@
+// This file defines a suite of tests for Resample.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// TestResampleIdentity confirms that resampling with the identity transform
+// and a box kernel reproduces an opaque source image.
+func TestResampleIdentity(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(3, 4, 8, 9))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i * 37)
+		if i%4 == 3 {
+			src.Pix[i] = 255
+		}
+	}
+	dst := NewWeightedNRGBA(src.Rect)
+	Resample(dst, src, FitTransform(src.Rect, dst.Rect), BoxKernel)
+	for y := src.Rect.Min.Y; y < src.Rect.Max.Y; y++ {
+		for x := src.Rect.Min.X; x < src.Rect.Max.X; x++ {
+			e := src.NRGBAAt(x, y)
+			if c := dst.ColorNRGBAAt(x, y); c != e {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", e, c, x, y)
+			}
+			if tally := dst.WeightedNRGBAAt(x, y).Tally; tally != 1.0 {
+				t.Fatalf("expected a tally of 1 but saw %v at (%d, %d)", tally, x, y)
+			}
+		}
+	}
+}
+
+// TestResampleUpscale upscales a linear gradient by a non-integer factor with
+// a triangle kernel and confirms that the interior remains linear.
+func TestResampleUpscale(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 8, 1))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i * 30)
+	}
+	dst := NewWeightedNRGBA(image.Rect(0, 0, 20, 1))
+	tr := FitTransform(src.Rect, dst.Rect)
+	Resample(dst, src, tr, TriangleKernel)
+
+	// Interpolating linearly between source pixel centers, destination
+	// pixel center x+0.5 lies at source coordinate (x+0.5)/2.5 - 0.5.
+	// Pixels near the edges lack source pixels on one side.
+	for x := 4; x < 16; x++ {
+		e := ((float64(x)+0.5)/tr.ScaleX - 0.5) * 30.0
+		c := dst.WeightedNRGBAAt(x, 0)
+		if r := c.R / c.Tally; math.Abs(r-e) > 1e-6 {
+			t.Fatalf("expected red = %.4f but saw %.4f at (%d, 0)", e, r, x)
+		}
+	}
+}
+
+// TestResampleCoverage confirms that destination pixels not covered by the
+// transformed source image retain a zero tally.
+func TestResampleCoverage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := range src.Pix {
+		src.Pix[i] = 255
+	}
+	tr := Transform{ScaleX: 1.5, ScaleY: 1.5, OffsetX: 2.25, OffsetY: 0.0}
+	dst := NewWeightedLabA(image.Rect(0, 0, 10, 10))
+	Resample(dst, src, tr, BoxKernel)
+
+	// The source spans destination x coordinates [2.25, 8.25) and y
+	// coordinates [0, 6).  Upscaling widens the kernel of each
+	// destination pixel by the scale factor, so the kernels of pixels
+	// whose centers lie within 0.75 of the source also cover it.
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			covered := x >= 2 && x <= 8 && y <= 6
+			tally := dst.WeightedLabAAt(x, y).Tally
+			switch {
+			case covered && tally <= 0.0:
+				t.Fatalf("expected (%d, %d) to be covered", x, y)
+			case !covered && tally != 0.0:
+				t.Fatalf("expected (%d, %d) not to be covered but saw a tally of %v", x, y, tally)
+			}
+		}
+	}
+}
+
+// TestResampleConstant confirms that resampling a constant-colored image
+// with each kernel, in each color space, produces the same constant color.
+func TestResampleConstant(t *testing.T) {
+	clr := color.NRGBA{30, 180, 90, 255}
+	src := image.NewNRGBA(image.Rect(0, 0, 7, 5))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 7; x++ {
+			src.SetNRGBA(x, y, clr)
+		}
+	}
+	for _, k := range []*Kernel{BoxKernel, TriangleKernel, LanczosKernel, MitchellKernel} {
+		for _, tr := range []Transform{
+			{ScaleX: 2.5, ScaleY: 1.75, OffsetX: 0.3, OffsetY: -0.6},
+			{ScaleX: 0.6, ScaleY: 0.45, OffsetX: 1.1, OffsetY: 0.2},
+		} {
+			dsts := []WeightedAccumImage{
+				NewWeightedNRGBA(image.Rect(0, 0, 12, 8)),
+				NewWeightedLabA(image.Rect(0, 0, 12, 8)),
+			}
+			for _, dst := range dsts {
+				Resample(dst, src, tr, k)
+				for y := 1; y < 3; y++ {
+					for x := 2; x < 4; x++ {
+						c := color.NRGBAModel.Convert(dst.At(x, y)).(color.NRGBA)
+						if absDiff(c.R, clr.R) > 1 || absDiff(c.G, clr.G) > 1 || absDiff(c.B, clr.B) > 1 || c.A != clr.A {
+							t.Fatalf("expected %v but saw %v at (%d, %d) of a %T", clr, c, x, y, dst)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestResampleArea confirms that downscaling with a box kernel weights each
+// source pixel by its area of overlap with each destination pixel and hence
+// agrees with Downscale.
+func TestResampleArea(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 3))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i * 23)
+		if i%4 == 3 {
+			src.Pix[i] = 255
+		}
+	}
+	r := image.Rect(0, 0, 2, 2)
+	exp := Downscale(src, r, NRGBASpace).(*NRGBA)
+	dst := NewNRGBA(r)
+	Resample(dst, src, FitTransform(src.Rect, r), BoxKernel)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			e := exp.ColorNRGBAAt(x, y)
+			c := dst.ColorNRGBAAt(x, y)
+			if absDiff(c.R, e.R) > 1 || absDiff(c.G, e.G) > 1 || absDiff(c.B, e.B) > 1 || c.A != e.A {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", e, c, x, y)
+			}
+		}
+	}
+
+	// Resampling a single row from 3 pixels to 2 should split the middle
+	// source pixel between both destination pixels.
+	red := color.NRGBA{R: 255, A: 255}
+	green := color.NRGBA{G: 255, A: 255}
+	row := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	row.SetNRGBA(0, 0, red)
+	row.SetNRGBA(1, 0, green)
+	row.SetNRGBA(2, 0, red)
+	wdst := NewWeightedNRGBA(image.Rect(0, 0, 2, 1))
+	Resample(wdst, row, FitTransform(row.Rect, wdst.Rect), BoxKernel)
+	total := 0.0
+	for x := 0; x < 2; x++ {
+		c := wdst.WeightedNRGBAAt(x, 0)
+		if e := 255.0 * 2.0 / 3.0; math.Abs(c.R/c.Tally-e) > 1e-6 {
+			t.Fatalf("expected red = %.4f but saw %.4f at (%d, 0)", e, c.R/c.Tally, x)
+		}
+		total += c.Tally
+	}
+	if math.Abs(total-3.0) > 1e-9 {
+		t.Fatalf("expected a total tally of 3 but saw %v", total)
+	}
+}
+
+// TestResampleIntegerTally confirms that Resample accumulates onto images
+// with integer tallies by scaling weights by WeightLevels.
+func TestResampleIntegerTally(t *testing.T) {
+	clr := color.NRGBA{30, 180, 90, 255}
+	src := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			src.SetNRGBA(x, y, clr)
+		}
+	}
+	for _, dst := range []AccumImage{
+		NewNRGBA(image.Rect(0, 0, 4, 4)),
+		NewLabA(image.Rect(0, 0, 4, 4)),
+		NewOkLabA(image.Rect(0, 0, 4, 4)),
+	} {
+		Resample(dst, src, FitTransform(src.Rect, dst.Bounds()), BoxKernel)
+		c := color.NRGBAModel.Convert(dst.At(1, 2)).(color.NRGBA)
+		if absDiff(c.R, clr.R) > 1 || absDiff(c.G, clr.G) > 1 || absDiff(c.B, clr.B) > 1 || c.A != clr.A {
+			t.Fatalf("expected %v but saw %v in a %T", clr, c, dst)
+		}
+		acc := dst.At(1, 2).(interface{ Weight() float64 })
+		if w := acc.Weight(); w != 4*WeightLevels {
+			t.Fatalf("expected a weight of %d but saw %v in a %T", 4*WeightLevels, w, dst)
+		}
+	}
+}
+
+// TestResampleUnsupported confirms that Resample panics when given an image
+// that cannot accumulate weighted colors.
+func TestResampleUnsupported(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Resample to panic on a MedianNRGBA")
+		}
+	}()
+	src := image.NewGray(image.Rect(0, 0, 2, 2))
+	dst := NewMedianNRGBA(image.Rect(0, 0, 1, 1))
+	Resample(dst, src, FitTransform(src.Rect, dst.Rect), BoxKernel)
+}
@@ -0,0 +1,208 @@
+// This file defines the RGBA64 type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// NOTE: Many of the functions and methods in this file were copied verbatim or
+// nearly verbatim from the Go standard library (image/image.go).
+
+// An RGBA64 is an in-memory image whose At method returns accumcolor.RGBA64
+// values.  Because colors are accumulated in alpha-premultiplied form, layering
+// partially transparent images onto an RGBA64 does not darken the result with
+// the (meaningless) color of transparent pixels.
+type RGBA64 struct {
+	// Pix holds the image's pixels, in R, G, B, A, Tally order. The pixel
+	// at (x, y) starts at Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)*5].
+	Pix []uint64
+	// Stride is the Pix stride (in uint64s) between vertically adjacent
+	// pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewRGBA64 returns a new RGBA64 image with the given bounds.
+func NewRGBA64(r image.Rectangle) *RGBA64 {
+	return &RGBA64{
+		Pix:    make([]uint64, pixelBufferLength(5, r, "RGBA64")),
+		Stride: 5 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *RGBA64) At(x, y int) color.Color {
+	return p.AccumRGBA64At(x, y)
+}
+
+// AccumRGBA64At returns the color of the pixel at (x, y) as an
+// accumcolor.RGBA64.
+func (p *RGBA64) AccumRGBA64At(x, y int) accumcolor.RGBA64 {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.RGBA64{}
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	return accumcolor.RGBA64{R: s[0], G: s[1], B: s[2], A: s[3], Tally: s[4]}
+}
+
+// ColorNRGBA64At returns the color of the pixel at (x, y) as a
+// non-alpha-premultiplied color.NRGBA64.
+func (p *RGBA64) ColorNRGBA64At(x, y int) color.NRGBA64 {
+	return p.AccumRGBA64At(x, y).NRGBA64()
+}
+
+// PixOffset returns the index of the first element of Pix that corresponds to
+// the pixel at (x, y).
+func (p *RGBA64) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*5
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *RGBA64) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the RGBA64's color model (always
+// accumcolor.RGBA64Model).
+func (p *RGBA64) ColorModel() color.Model {
+	return accumcolor.RGBA64Model
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *RGBA64) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 3, p.Rect.Dx()*5
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for i := i0; i < i1; i += 5 {
+			tally := p.Pix[i+1]
+			if tally == 0 {
+				return false // No color at this position
+			}
+			if p.Pix[i] != 0xffff*tally {
+				return false // Not fully opaque
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *RGBA64) RGBA64At(x, y int) color.RGBA64 {
+	return p.AccumRGBA64At(x, y).RGBA64()
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *RGBA64) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	c1 := accumcolor.RGBA64Model.Convert(c).(accumcolor.RGBA64)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = c1.R
+	s[1] = c1.G
+	s[2] = c1.B
+	s[3] = c1.A
+	s[4] = c1.Tally
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *RGBA64) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	c1 := accumcolor.RGBA64Model.Convert(c).(accumcolor.RGBA64)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += c1.R
+	s[1] += c1.G
+	s[2] += c1.B
+	s[3] += c1.A
+	s[4] += c1.Tally
+}
+
+// SetAccumRGBA64 sets the pixel at (x, y) to a given color of type
+// accumcolor.RGBA64.
+func (p *RGBA64) SetAccumRGBA64(x, y int, c accumcolor.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = c.R
+	s[1] = c.G
+	s[2] = c.B
+	s[3] = c.A
+	s[4] = c.Tally
+}
+
+// AddAccumRGBA64 accumulates a given color of type accumcolor.RGBA64 to the
+// pixel at (x, y).
+func (p *RGBA64) AddAccumRGBA64(x, y int, c accumcolor.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += c.R
+	s[1] += c.G
+	s[2] += c.B
+	s[3] += c.A
+	s[4] += c.Tally
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *RGBA64) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] = uint64(c.R)
+	s[1] = uint64(c.G)
+	s[2] = uint64(c.B)
+	s[3] = uint64(c.A)
+	s[4] = 1
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at (x, y).
+func (p *RGBA64) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	s := p.Pix[i : i+5 : i+5] // Small cap improves performance, see https://golang.org/issue/27857
+	s[0] += uint64(c.R)
+	s[1] += uint64(c.G)
+	s[2] += uint64(c.B)
+	s[3] += uint64(c.A)
+	s[4]++
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *RGBA64) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &RGBA64{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &RGBA64{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
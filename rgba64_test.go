@@ -0,0 +1,47 @@
+// This file defines a suite of tests for accumimage.RGBA64.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestRGBA64Layers composites an opaque sprite with a fully transparent layer
+// and confirms that the transparent layer does not darken the sprite's color.
+func TestRGBA64Layers(t *testing.T) {
+	// Accumulate an opaque sprite onto the left half of an image and a
+	// transparent layer onto the entire image.
+	img := NewRGBA64(image.Rect(0, 0, 4, 4))
+	sprite := color.NRGBA{R: 240, G: 120, B: 60, A: 255}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if x < 2 {
+				img.Add(x, y, sprite)
+			}
+			img.AddRGBA64(x, y, color.RGBA64{})
+		}
+	}
+
+	// Confirm that the left half retains the sprite's color at half
+	// opacity and that the right half is transparent.
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			exp := color.NRGBA64{}
+			if x < 2 {
+				exp = color.NRGBA64{R: 240 * 257, G: 120 * 257, B: 60 * 257, A: 0x7fff}
+			}
+			act := img.ColorNRGBA64At(x, y)
+			if act != exp {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", exp, act, x, y)
+			}
+			if tally := img.AccumRGBA64At(x, y).Tally; tally == 0 {
+				t.Fatalf("expected a nonzero tally at (%d, %d)", x, y)
+			}
+		}
+	}
+	if img.Opaque() {
+		t.Fatal("expected the image not to be opaque")
+	}
+}
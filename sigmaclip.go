@@ -0,0 +1,130 @@
+// This file defines functions for stacking images with kappa-sigma clipping.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// A ClipMode specifies how sigma-clipped stacking treats samples that lie
+// more than kappa standard deviations from a pixel's mean.
+type ClipMode int
+
+const (
+	// ClipReject discards outlying samples entirely.
+	ClipReject ClipMode = iota
+
+	// ClipWinsorize replaces each outlying channel value with the nearest
+	// value that lies within kappa standard deviations of the mean.
+	ClipWinsorize
+)
+
+// clipEpsilon provides a small amount of slack when comparing a value's
+// distance from the mean to kappa standard deviations.  This prevents
+// round-off error from rejecting samples from pixels with zero variance.
+const clipEpsilon = 1e-9
+
+// clipChannel compares a value v to the range mean ± kappa*sd.  It returns
+// the value, clamped to that range, and a flag indicating whether the value
+// lay within the range.
+func clipChannel(v, mean, sd, kappa float64) (float64, bool) {
+	lim := kappa*sd + clipEpsilon
+	switch {
+	case v < mean-lim:
+		return mean - lim, false
+	case v > mean+lim:
+		return mean + lim, false
+	default:
+		return v, true
+	}
+}
+
+// forEachSample invokes a function on each pixel of each frame that lies
+// within a given rectangle.
+func forEachSample(r image.Rectangle, frames []image.Image, f func(x, y int, c color.Color)) {
+	for _, frame := range frames {
+		fr := r.Intersect(frame.Bounds())
+		for y := fr.Min.Y; y < fr.Max.Y; y++ {
+			for x := fr.Min.X; x < fr.Max.X; x++ {
+				f(x, y, frame.At(x, y))
+			}
+		}
+	}
+}
+
+// SigmaClipNRGBA stacks a sequence of frames into a new NRGBA image with
+// bounds r, averaging each pixel's samples after clipping outliers.  A first
+// pass over the frames computes each pixel's per-channel mean and standard
+// deviation.  A second pass re-accumulates each sample, treating any sample
+// with a channel lying more than kappa standard deviations from the mean as
+// specified by mode.  With ClipReject, a pixel at which every sample is
+// rejected is left with a zero tally.
+func SigmaClipNRGBA(r image.Rectangle, frames []image.Image, kappa float64, mode ClipMode) *NRGBA {
+	// Pass 1: Compute the mean and variance of each pixel.
+	stats := NewNRGBAStats(r)
+	forEachSample(r, frames, stats.Add)
+
+	// Pass 2: Accumulate only the samples that lie sufficiently close to
+	// the mean.
+	img := NewNRGBA(r)
+	forEachSample(r, frames, func(x, y int, c color.Color) {
+		st := stats.NRGBAStatsAt(x, y)
+		mr, mg, mb, ma := st.Mean()
+		sr, sg, sb, sa := st.StdDev()
+		nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+		vr, okR := clipChannel(float64(nrgba.R), mr, sr, kappa)
+		vg, okG := clipChannel(float64(nrgba.G), mg, sg, kappa)
+		vb, okB := clipChannel(float64(nrgba.B), mb, sb, kappa)
+		va, okA := clipChannel(float64(nrgba.A), ma, sa, kappa)
+		if mode == ClipReject && !(okR && okG && okB && okA) {
+			return
+		}
+		img.AddNRGBA(x, y, accumcolor.NRGBA{
+			R:     uint64(vr + 0.5),
+			G:     uint64(vg + 0.5),
+			B:     uint64(vb + 0.5),
+			A:     uint64(va + 0.5),
+			Tally: 1,
+		})
+	})
+	return img
+}
+
+// SigmaClipLabA stacks a sequence of frames into a new LabA image with bounds
+// r, averaging each pixel's samples after clipping outliers.  It is analogous
+// to SigmaClipNRGBA but computes means and standard deviations in the CIE
+// L*a*b* color space.
+func SigmaClipLabA(r image.Rectangle, frames []image.Image, kappa float64, mode ClipMode) *LabA {
+	// Pass 1: Compute the mean and variance of each pixel.
+	stats := NewLabAStats(r)
+	forEachSample(r, frames, stats.Add)
+
+	// Pass 2: Accumulate only the samples that lie sufficiently close to
+	// the mean.
+	img := NewLabA(r)
+	forEachSample(r, frames, func(x, y int, c color.Color) {
+		st := stats.LabAStatsAt(x, y)
+		mL, ma, mb, mAlpha := st.Mean()
+		sL, sa, sb, sAlpha := st.StdDev()
+		lab := accumcolor.LabAModel.Convert(c).(accumcolor.LabA)
+		vL, okL := clipChannel(lab.L, mL, sL, kappa)
+		va, okA := clipChannel(lab.A, ma, sa, kappa)
+		vb, okB := clipChannel(lab.B, mb, sb, kappa)
+		vAlpha, okAlpha := clipChannel(float64(lab.Alpha), mAlpha, sAlpha, kappa)
+		if mode == ClipReject && !(okL && okA && okB && okAlpha) {
+			return
+		}
+		img.AddLabA(x, y, accumcolor.LabA{
+			L:     vL,
+			A:     va,
+			B:     vb,
+			Alpha: uint64(math.Round(vAlpha)),
+			Tally: 1,
+		})
+	})
+	return img
+}
@@ -0,0 +1,75 @@
+// This file defines a suite of tests for sigma-clipped stacking.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// makeFrames returns n 2x1 frames of a given color.  The pixel at (1, 0) in
+// the final frame is replaced with a given outlier color.
+func makeFrames(n int, c, outlier color.Color) []image.Image {
+	frames := make([]image.Image, n)
+	for i := range frames {
+		img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+		img.Set(0, 0, c)
+		img.Set(1, 0, c)
+		frames[i] = img
+	}
+	frames[n-1].(*image.NRGBA).Set(1, 0, outlier)
+	return frames
+}
+
+// TestSigmaClipNRGBA confirms that sigma clipping removes or tames an outlier
+// in one of a sequence of frames.
+func TestSigmaClipNRGBA(t *testing.T) {
+	c := color.NRGBA{R: 50, G: 50, B: 50, A: 255}
+	outlier := color.NRGBA{R: 250, G: 50, B: 50, A: 255}
+	frames := makeFrames(10, c, outlier)
+	r := image.Rect(0, 0, 3, 1)
+
+	// Rejecting the outlier should leave the pixel unaffected.
+	img := SigmaClipNRGBA(r, frames, 2.0, ClipReject)
+	for x := 0; x < 2; x++ {
+		if act := img.ColorNRGBAAt(x, 0); act != c {
+			t.Fatalf("expected %v but saw %v at (%d, 0)", c, act, x)
+		}
+	}
+	if tally := img.NRGBAAt(1, 0).Tally; tally != 9 {
+		t.Fatalf("expected a tally of 9 but saw %d", tally)
+	}
+	if tally := img.NRGBAAt(2, 0).Tally; tally != 0 {
+		t.Fatalf("expected a tally of 0 but saw %d", tally)
+	}
+
+	// Winsorizing the outlier should clamp it to mean + 2*sigma, which is
+	// 70 + 2*sqrt(4000).
+	img = SigmaClipNRGBA(r, frames, 2.0, ClipWinsorize)
+	exp := color.NRGBA{R: 64, G: 50, B: 50, A: 255}
+	if act := img.ColorNRGBAAt(1, 0); act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+	if tally := img.NRGBAAt(1, 0).Tally; tally != 10 {
+		t.Fatalf("expected a tally of 10 but saw %d", tally)
+	}
+}
+
+// TestSigmaClipLabA confirms that sigma clipping in L*a*b* space rejects an
+// outlier in one of a sequence of frames.
+func TestSigmaClipLabA(t *testing.T) {
+	c := colorful.Lab(0.5, 0.1, -0.1).Clamped()
+	frames := makeFrames(10, c, color.White)
+	img := SigmaClipLabA(image.Rect(0, 0, 2, 1), frames, 2.0, ClipReject)
+	exp := img.LabAAt(0, 0).Average()
+	act := img.LabAAt(1, 0).Average()
+	if act != exp {
+		t.Fatalf("expected %v but saw %v", exp, act)
+	}
+	if tally := img.LabAAt(1, 0).Tally; tally != 9 {
+		t.Fatalf("expected a tally of 9 but saw %d", tally)
+	}
+}
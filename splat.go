@@ -0,0 +1,54 @@
+// This file defines a helper function for splatting colors at sub-pixel
+// coordinates.
+
+package accumimage
+
+import (
+	"image"
+	"math"
+)
+
+// axisWeights returns the index of the first pixel that lies within a
+// kernel's support when the kernel is centered at coordinate c along one
+// axis, followed by the kernel weight of each pixel in turn.
+func axisWeights(k *Kernel, c float64) (int, []float64) {
+	lo := int(math.Ceil(c - 0.5 - k.Support))
+	hi := int(math.Floor(c - 0.5 + k.Support))
+	if hi < lo {
+		return lo, nil
+	}
+	ws := make([]float64, 0, hi-lo+1)
+	for i := lo; i <= hi; i++ {
+		ws = append(ws, math.Max(k.At(float64(i)+0.5-c), 0.0))
+	}
+	return lo, ws
+}
+
+// splat distributes a total weight of 1 across the pixels near the point (x,
+// y) in proportion to a kernel centered on that point.  Pixel (i, j) is
+// considered to be centered at (i+0.5, j+0.5).  splat invokes a function on
+// each pixel within r that receives a positive weight, passing it the pixel's
+// coordinates and weight.  Weight that falls outside of r is discarded, not
+// redistributed to pixels within r.
+func splat(r image.Rectangle, x, y float64, k *Kernel, f func(x, y int, w float64)) {
+	x0, xs := axisWeights(k, x)
+	y0, ys := axisWeights(k, y)
+	total := 0.0
+	for _, wy := range ys {
+		for _, wx := range xs {
+			total += wx * wy
+		}
+	}
+	if total <= 0.0 {
+		return
+	}
+	for j, wy := range ys {
+		for i, wx := range xs {
+			w := wx * wy / total
+			pt := image.Point{x0 + i, y0 + j}
+			if w > 0.0 && pt.In(r) {
+				f(pt.X, pt.Y, w)
+			}
+		}
+	}
+}
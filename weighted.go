@@ -0,0 +1,98 @@
+// This file provides support for accumulating colors with fractional weights
+// onto images whose pixels maintain integer tallies.
+
+package accumimage
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// WeightLevels is the integer weight with which an image returned by Weighted
+// accumulates a color given a weight of 1 if the underlying image maintains
+// integer tallies.  Other weights are scaled proportionally.
+const WeightLevels = 255
+
+// integerWeighted wraps an AccumImage whose pixels maintain integer tallies
+// to make it a WeightedAccumImage.
+type integerWeighted struct {
+	AccumImage
+	add func(x, y int, c color.Color, n float64) // Accumulate with integer weight n
+}
+
+// AddWeighted accumulates a given color of any type to the pixel at (x, y)
+// with a given nonnegative weight, which is multiplied by WeightLevels and
+// rounded to the nearest integer.  Colors whose weight rounds to zero are
+// discarded.
+func (p integerWeighted) AddWeighted(x, y int, c color.Color, w float64) {
+	n := math.Round(w * WeightLevels)
+	if n > 0.0 {
+		p.add(x, y, c, n)
+	}
+}
+
+// integerAdder returns a function that converts a color to a C using a given
+// color model, scales it by an integer weight, and accumulates it with a given
+// function.
+func integerAdder[C color.Color, P AccumulatorPtr[C]](model color.Model, add func(x, y int, c C)) func(x, y int, c color.Color, n float64) {
+	return func(x, y int, c color.Color, n float64) {
+		clr := model.Convert(c).(C)
+		if P(&clr).ScaleWeight(n) == nil {
+			add(x, y, clr)
+		}
+	}
+}
+
+// Weighted returns an AccumImage as a WeightedAccumImage.  If img's pixels
+// maintain fractional tallies, Weighted returns img unmodified.  If instead
+// img's pixels maintain integer tallies, Weighted wraps img so that
+// AddWeighted multiplies each weight by WeightLevels and rounds it to the
+// nearest integer.
+// Weighted returns nil if img can accumulate neither fractional nor integer
+// weights, as is the case for a MedianNRGBA.
+func Weighted(img AccumImage) WeightedAccumImage {
+	var add func(x, y int, c color.Color, n float64)
+	switch img := img.(type) {
+	case interface{ weighted() WeightedAccumImage }:
+		return img.weighted()
+	case WeightedAccumImage:
+		return img
+	case *NRGBA:
+		add = integerAdder[accumcolor.NRGBA](accumcolor.NRGBAModel, img.AddNRGBA)
+	case *NRGBA64:
+		add = integerAdder[accumcolor.NRGBA64](accumcolor.NRGBA64Model, img.AddNRGBA64)
+	case *Gray:
+		add = integerAdder[accumcolor.Gray](accumcolor.GrayModel, img.AddGray)
+	case *Gray16:
+		add = integerAdder[accumcolor.Gray16](accumcolor.Gray16Model, img.AddGray16)
+	case *LinearNRGBA:
+		add = integerAdder[accumcolor.LinearNRGBA](accumcolor.LinearNRGBAModel, img.AddLinearNRGBA)
+	case *RGBA64:
+		add = integerAdder[accumcolor.RGBA64](accumcolor.RGBA64Model, img.AddAccumRGBA64)
+	case *LabA:
+		add = integerAdder[accumcolor.LabA](accumcolor.LabAModel, img.AddLabA)
+	case *CompensatedLabA:
+		add = integerAdder[accumcolor.CompensatedLabA](accumcolor.CompensatedLabAModel, img.AddCompensatedLabA)
+	case *OkLabA:
+		add = integerAdder[accumcolor.OkLabA](accumcolor.OkLabAModel, img.AddOkLabA)
+	case *LChA:
+		add = integerAdder[accumcolor.LChA](accumcolor.LChAModel, img.AddLChA)
+	case *HSVA:
+		add = integerAdder[accumcolor.HSVA](accumcolor.HSVAModel, img.AddHSVA)
+	case *HSLA:
+		add = integerAdder[accumcolor.HSLA](accumcolor.HSLAModel, img.AddHSLA)
+	case *NRGBAStats:
+		add = integerAdder[accumcolor.NRGBAStats](accumcolor.NRGBAStatsModel, img.AddNRGBAStats)
+	case *LabAStats:
+		add = integerAdder[accumcolor.LabAStats](accumcolor.LabAStatsModel, img.AddLabAStats)
+	case *MinNRGBA:
+		add = integerAdder[accumcolor.MinNRGBA](accumcolor.MinNRGBAModel, img.AddMinNRGBA)
+	case *MaxNRGBA:
+		add = integerAdder[accumcolor.MaxNRGBA](accumcolor.MaxNRGBAModel, img.AddMaxNRGBA)
+	default:
+		return nil
+	}
+	return integerWeighted{AccumImage: img, add: add}
+}
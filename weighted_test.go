@@ -0,0 +1,34 @@
+// This file defines a suite of tests for Weighted.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// TestWeighted confirms that Weighted passes fractional weights through to a
+// WeightedAccumImage, scales weights by WeightLevels for an image with integer
+// tallies, and rejects images that support neither.
+func TestWeighted(t *testing.T) {
+	r := image.Rect(0, 0, 2, 2)
+	wimg := NewWeightedNRGBA(r)
+	if Weighted(wimg) != WeightedAccumImage(wimg) {
+		t.Fatal("expected Weighted to return a WeightedNRGBA unmodified")
+	}
+
+	gray := NewGray(r)
+	wgray := Weighted(gray)
+	wgray.AddWeighted(1, 1, color.Gray{Y: 100}, 0.5)
+	wgray.AddWeighted(1, 1, color.Gray{Y: 100}, 0.001)
+	if c := gray.GrayAt(1, 1); c != (accumcolor.Gray{Y: 100 * 128, Tally: 128}) {
+		t.Fatalf("expected a tally of 128 but saw %v", c)
+	}
+
+	if Weighted(NewMedianNRGBA(r)) != nil {
+		t.Fatal("expected Weighted to reject a MedianNRGBA")
+	}
+}
@@ -0,0 +1,119 @@
+// This file defines a suite of tests for accumimage.WeightedLabA.
+
+package accumimage
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// TestWeightedLabAAddWeighted accumulates colors with fractional weights into
+// a subimage and confirms that the original image reflects the change.
+func TestWeightedLabAAddWeighted(t *testing.T) {
+	img := NewWeightedLabA(image.Rect(0, 0, 4, 4))
+	sub := img.SubImage(image.Rect(2, 2, 4, 4)).(*WeightedLabA)
+	c1 := accumcolor.LabA{L: 0.2, A: 0.4, B: -0.4, Alpha: 255, Tally: 1}
+	c2 := accumcolor.LabA{L: 0.8, A: -0.2, B: 0.2, Alpha: 255, Tally: 1}
+	for y := 2; y < 4; y++ {
+		for x := 2; x < 4; x++ {
+			sub.AddWeighted(x, y, c1, 0.5)
+			sub.AddWeighted(x, y, c2, 0.25)
+		}
+	}
+	approxEqual := func(a, b float64) bool {
+		return math.Abs(a-b) < 1e-6
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			c := img.WeightedLabAAt(x, y)
+			if x < 2 || y < 2 {
+				if c.Tally != 0.0 {
+					t.Fatalf("expected no color at (%d, %d)", x, y)
+				}
+				continue
+			}
+			avg := c.Average()
+			if !approxEqual(c.Tally, 0.75) ||
+				!approxEqual(avg.L, 0.4) ||
+				!approxEqual(avg.A, 0.2) ||
+				!approxEqual(avg.B, -0.2) ||
+				!approxEqual(avg.Alpha, 255.0) {
+				t.Fatalf("incorrect color %v at (%d, %d)", c, x, y)
+			}
+		}
+	}
+}
+
+// TestWeightedLabADecay confirms that decaying an image between
+// accumulations produces an exponential moving average.
+func TestWeightedLabADecay(t *testing.T) {
+	img := NewWeightedLabA(image.Rect(0, 0, 1, 1))
+	const factor = 0.8
+	const n = 200
+	for i := 0; i < n; i++ {
+		img.Decay(factor)
+		L := 0.2
+		if i%2 == 1 {
+			L = 0.6
+		}
+		img.Add(0, 0, accumcolor.LabA{L: L, Alpha: 255, Tally: 1})
+	}
+
+	// The tally should approach 1/(1-factor), and the average should be
+	// biased toward the most recent color, 0.6.
+	c := img.WeightedLabAAt(0, 0)
+	if math.Abs(c.Tally-1.0/(1.0-factor)) > 1e-6 {
+		t.Fatalf("expected a tally of %v but saw %v", 1.0/(1.0-factor), c.Tally)
+	}
+	exp := (0.6 + 0.2*factor) / (1.0 + factor)
+	if L := c.Average().L; math.Abs(L-exp) > 1e-6 {
+		t.Fatalf("expected L = %v but saw %v", exp, L)
+	}
+}
+
+// TestWeightedLabADecayInvalid confirms that Decay rejects a factor outside
+// [0, 1].
+func TestWeightedLabADecayInvalid(t *testing.T) {
+	img := NewWeightedLabA(image.Rect(0, 0, 1, 1))
+	for _, factor := range []float64{-0.5, 1.5, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected Decay to panic on a factor of %v", factor)
+				}
+			}()
+			img.Decay(factor)
+		}()
+	}
+}
+
+// TestWeightedLabAAddAt splats a color at a sub-pixel location near the edge
+// of a subimage and confirms that only the portion within the subimage is
+// accumulated.
+func TestWeightedLabAAddAt(t *testing.T) {
+	img := NewWeightedLabA(image.Rect(0, 0, 4, 4))
+	sub := img.SubImage(image.Rect(2, 0, 4, 4)).(*WeightedLabA)
+	clr := accumcolor.WeightedLabA{L: 0.5, A: 0.25, B: -0.25, Alpha: 255, Tally: 1}
+	sub.AddAt(2.25, 1.0, clr)
+	exp := map[image.Point]float64{
+		{2, 0}: 0.375,
+		{2, 1}: 0.375,
+	}
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			c := img.WeightedLabAAt(x, y)
+			if e := exp[image.Point{x, y}]; math.Abs(c.Tally-e) > 1e-12 {
+				t.Fatalf("expected a tally of %v but saw %v at (%d, %d)", e, c.Tally, x, y)
+			}
+			if c.Tally == 0.0 {
+				continue
+			}
+			if L := c.Average().L; math.Abs(L-clr.L) > 1e-12 {
+				t.Fatalf("expected L = %v but saw %v at (%d, %d)", clr.L, L, x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// This file defines the WeightedNRGBA type and associated methods.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/spakin/accumimage/v2/accumcolor"
+)
+
+// A WeightedNRGBA is an in-memory image whose At method returns
+// accumcolor.WeightedNRGBA values.  Unlike an NRGBA, a WeightedNRGBA supports
+// accumulating colors with real-valued weights.
+type WeightedNRGBA struct {
+	// Pix holds the image's pixels.  The pixel at (x, y) is
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []accumcolor.WeightedNRGBA
+	// Stride is the Pix stride (in accumcolor.WeightedNRGBAs) between
+	// vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewWeightedNRGBA returns a new WeightedNRGBA image with the given bounds.
+func NewWeightedNRGBA(r image.Rectangle) *WeightedNRGBA {
+	return &WeightedNRGBA{
+		Pix:    make([]accumcolor.WeightedNRGBA, pixelBufferLength(1, r, "WeightedNRGBA")),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// At returns the color of the pixel at (x, y) as a color.Color.
+func (p *WeightedNRGBA) At(x, y int) color.Color {
+	return p.WeightedNRGBAAt(x, y)
+}
+
+// WeightedNRGBAAt returns the color of the pixel at (x, y) as an
+// accumcolor.WeightedNRGBA.
+func (p *WeightedNRGBA) WeightedNRGBAAt(x, y int) accumcolor.WeightedNRGBA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return accumcolor.WeightedNRGBA{}
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// ColorNRGBAAt returns the color of the pixel at (x, y) as a color.NRGBA.
+func (p *WeightedNRGBA) ColorNRGBAAt(x, y int) color.NRGBA {
+	return p.WeightedNRGBAAt(x, y).NRGBA()
+}
+
+// PixOffset returns the index of the element of Pix that corresponds to the
+// pixel at (x, y).
+func (p *WeightedNRGBA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (p *WeightedNRGBA) Bounds() image.Rectangle { return p.Rect }
+
+// ColorModel returns the WeightedNRGBA's color model (always
+// accumcolor.WeightedNRGBAModel).
+func (p *WeightedNRGBA) ColorModel() color.Model {
+	return accumcolor.WeightedNRGBAModel
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *WeightedNRGBA) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for _, clr := range p.Pix[i0:i1] {
+			if clr.Tally == 0.0 {
+				return false // No color at this position
+			}
+			if clr.A/clr.Tally < 254.5 {
+				return false // Average alpha rounds to less than 255
+			}
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+	return true
+}
+
+// RGBA64At returns the color of the pixel at (x, y) as a color.RGBA64.
+func (p *WeightedNRGBA) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := p.WeightedNRGBAAt(x, y).RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// Set sets the pixel at (x, y) to a given color of any type.
+func (p *WeightedNRGBA) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.WeightedNRGBAModel.Convert(c).(accumcolor.WeightedNRGBA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// Add accumulates a given color of any type to the pixel at (x, y).
+func (p *WeightedNRGBA) Add(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.WeightedNRGBAModel.Convert(c).(accumcolor.WeightedNRGBA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// AddWeighted accumulates a given color of any type to the pixel at (x, y)
+// with a given nonnegative weight.
+func (p *WeightedNRGBA) AddWeighted(x, y int, c color.Color, w float64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].AddWeighted(c, w)
+}
+
+// AddAt accumulates a given color of any type at the sub-pixel location (x,
+// y), where pixel (i, j) is centered at (i+0.5, j+0.5).  The color is
+// distributed across the four nearest pixels with bilinear weights that sum
+// to 1.  Any portion of the color that lands outside the image is discarded.
+func (p *WeightedNRGBA) AddAt(x, y float64, c color.Color) {
+	p.AddAtKernel(x, y, c, TriangleKernel)
+}
+
+// AddAtKernel is like AddAt but distributes the color across nearby pixels in
+// proportion to a given kernel, such as one returned by GaussianKernel.
+// Kernel weights are clamped to be nonnegative.
+func (p *WeightedNRGBA) AddAtKernel(x, y float64, c color.Color, k *Kernel) {
+	clr := accumcolor.WeightedNRGBAModel.Convert(c).(accumcolor.WeightedNRGBA)
+	splat(p.Rect, x, y, k, func(x, y int, w float64) {
+		p.Pix[p.PixOffset(x, y)].AddWeighted(clr, w)
+	})
+}
+
+// SetWeightedNRGBA sets the pixel at (x, y) to a given color of type
+// accumcolor.WeightedNRGBA.
+func (p *WeightedNRGBA) SetWeightedNRGBA(x, y int, c accumcolor.WeightedNRGBA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = c
+}
+
+// AddWeightedNRGBA accumulates a given color of type
+// accumcolor.WeightedNRGBA to the pixel at (x, y).
+func (p *WeightedNRGBA) AddWeightedNRGBA(x, y int, c accumcolor.WeightedNRGBA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)].Add(c)
+}
+
+// SetRGBA64 sets the pixel at (x, y) to a given color of type color.RGBA64.
+func (p *WeightedNRGBA) SetRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.WeightedNRGBAModel.Convert(c).(accumcolor.WeightedNRGBA)
+	p.Pix[p.PixOffset(x, y)] = clr
+}
+
+// AddRGBA64 accumulates a given color of type color.RGBA64 to the pixel at
+// (x, y).
+func (p *WeightedNRGBA) AddRGBA64(x, y int, c color.RGBA64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	clr := accumcolor.WeightedNRGBAModel.Convert(c).(accumcolor.WeightedNRGBA)
+	p.Pix[p.PixOffset(x, y)].Add(clr)
+}
+
+// Decay multiplies every pixel's sums and tally by a given factor in [0, 1].
+// This fades out previously accumulated colors without changing any pixel's
+// current average.  Calling Decay before accumulating each new set of colors
+// thereby maintains an exponential moving average of those colors.  Decay
+// panics if factor lies outside [0, 1] or is NaN.
+func (p *WeightedNRGBA) Decay(factor float64) {
+	if !(factor >= 0.0 && factor <= 1.0) {
+		panic("accumimage: Decay factor is not in [0, 1]")
+	}
+	i0, i1 := 0, p.Rect.Dx()
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for i := i0; i < i1; i++ {
+			p.Pix[i].Scale(factor)
+		}
+		i0 += p.Stride
+		i1 += p.Stride
+	}
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *WeightedNRGBA) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to
+	// be inside either r1 or r2 if the intersection is empty. Without
+	// explicitly checking for this, the Pix[i:] expression below can
+	// panic.
+	if r.Empty() {
+		return &WeightedNRGBA{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &WeightedNRGBA{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
@@ -0,0 +1,168 @@
+// This file defines a suite of tests for accumimage.WeightedNRGBA.
+
+package accumimage
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// TestWeightedNRGBAAddWeighted splats a color across two pixels with
+// fractional weights and confirms that the colors and tallies are as
+// expected.
+func TestWeightedNRGBAAddWeighted(t *testing.T) {
+	img := NewWeightedNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{B: 200, A: 255})
+	img.Set(1, 0, color.NRGBA{B: 200, A: 255})
+	red := color.NRGBA{R: 200, A: 255}
+	img.AddWeighted(0, 0, red, 0.25)
+	img.AddWeighted(1, 0, red, 0.75)
+	img.AddWeighted(2, 0, red, 1.0) // Out of bounds; should be ignored
+
+	// Check the colors.
+	exp := []color.NRGBA{
+		{R: 40, G: 0, B: 160, A: 255},
+		{R: 86, G: 0, B: 114, A: 255},
+	}
+	for x, e := range exp {
+		if act := img.ColorNRGBAAt(x, 0); act != e {
+			t.Fatalf("expected %v but saw %v at (%d, 0)", e, act, x)
+		}
+	}
+
+	// Check the tallies.
+	if tally := img.WeightedNRGBAAt(0, 0).Tally; tally != 1.25 {
+		t.Fatalf("expected a tally of 1.25 but saw %v", tally)
+	}
+	if tally := img.WeightedNRGBAAt(1, 0).Tally; tally != 1.75 {
+		t.Fatalf("expected a tally of 1.75 but saw %v", tally)
+	}
+	if !img.Opaque() {
+		t.Fatal("expected the image to be opaque")
+	}
+}
+
+// TestWeightedNRGBADecay maintains an exponential moving average of a
+// sequence of colors and confirms that the result is as expected.
+func TestWeightedNRGBADecay(t *testing.T) {
+	img := NewWeightedNRGBA(image.Rect(0, 0, 2, 1))
+	sub := img.SubImage(image.Rect(1, 0, 2, 1)).(*WeightedNRGBA)
+	for i, r := range []uint8{0, 120, 240} {
+		if i > 0 {
+			sub.Decay(0.5)
+		}
+		img.Add(0, 0, color.NRGBA{R: r, A: 255})
+		img.Add(1, 0, color.NRGBA{R: r, A: 255})
+	}
+
+	// The sub-image's pixel should weight recent colors more heavily.
+	exp := []struct {
+		c     color.NRGBA
+		tally float64
+	}{
+		{color.NRGBA{R: 120, A: 255}, 3.0},
+		{color.NRGBA{R: 171, A: 255}, 1.75},
+	}
+	for x, e := range exp {
+		if act := img.ColorNRGBAAt(x, 0); act != e.c {
+			t.Fatalf("expected %v but saw %v at (%d, 0)", e.c, act, x)
+		}
+		if tally := img.WeightedNRGBAAt(x, 0).Tally; tally != e.tally {
+			t.Fatalf("expected a tally of %v but saw %v at (%d, 0)", e.tally, tally, x)
+		}
+	}
+}
+
+// TestWeightedNRGBADecayInvalid confirms that Decay rejects a factor outside
+// [0, 1].
+func TestWeightedNRGBADecayInvalid(t *testing.T) {
+	img := NewWeightedNRGBA(image.Rect(0, 0, 1, 1))
+	for _, factor := range []float64{-0.5, 1.5, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected Decay to panic on a factor of %v", factor)
+				}
+			}()
+			img.Decay(factor)
+		}()
+	}
+}
+
+// TestWeightedNRGBAAddAt splats colors at sub-pixel locations, including one
+// that lies partly outside the image, and confirms that the tallies are as
+// expected.
+func TestWeightedNRGBAAddAt(t *testing.T) {
+	img := NewWeightedNRGBA(image.Rect(0, 0, 4, 3))
+	red := color.NRGBA{R: 200, A: 255}
+	img.AddAt(2.75, 1.5, red)
+	img.AddAt(0.25, 0.5, red) // Partly out of bounds
+	exp := map[image.Point]float64{
+		{2, 1}: 0.75,
+		{3, 1}: 0.25,
+		{0, 0}: 0.75,
+	}
+	total := 0.0
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			c := img.WeightedNRGBAAt(x, y)
+			if e := exp[image.Point{x, y}]; c.Tally != e {
+				t.Fatalf("expected a tally of %v but saw %v at (%d, %d)", e, c.Tally, x, y)
+			}
+			if c.Tally > 0.0 && img.ColorNRGBAAt(x, y) != red {
+				t.Fatalf("expected %v but saw %v at (%d, %d)", red, img.ColorNRGBAAt(x, y), x, y)
+			}
+			total += c.Tally
+		}
+	}
+	if total != 1.75 {
+		t.Fatalf("expected a total tally of 1.75 but saw %v", total)
+	}
+}
+
+// TestWeightedNRGBAAddAtKernel splats a color with a Gaussian kernel and
+// confirms that the weights are symmetric and sum to 1.
+func TestWeightedNRGBAAddAtKernel(t *testing.T) {
+	img := NewWeightedNRGBA(image.Rect(0, 0, 9, 9))
+	img.AddAtKernel(4.5, 4.5, color.NRGBA{G: 100, A: 255}, GaussianKernel(1.0))
+	total := 0.0
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 9; x++ {
+			w := img.WeightedNRGBAAt(x, y).Tally
+			if m := img.WeightedNRGBAAt(8-y, x).Tally; math.Abs(w-m) > 1e-12 {
+				t.Fatalf("expected symmetric weights but saw %v at (%d, %d) and %v at (%d, %d)", w, x, y, m, 8-y, x)
+			}
+			total += w
+		}
+	}
+	if math.Abs(total-1.0) > 1e-12 {
+		t.Fatalf("expected a total tally of 1 but saw %v", total)
+	}
+	if c, e := img.WeightedNRGBAAt(4, 4).Tally, img.WeightedNRGBAAt(4, 3).Tally; c <= e {
+		t.Fatalf("expected the center weight (%v) to exceed its neighbor's (%v)", c, e)
+	}
+}
+
+// TestWeightedNRGBAAddAtDegenerate confirms that splatting with a kernel whose
+// support is empty accumulates nothing and that GaussianKernel rejects a
+// nonpositive standard deviation.
+func TestWeightedNRGBAAddAtDegenerate(t *testing.T) {
+	img := NewWeightedNRGBA(image.Rect(0, 0, 3, 3))
+	k := &Kernel{Support: -1.0, At: func(float64) float64 { return 1.0 }}
+	img.AddAtKernel(1.5, 1.5, color.White, k)
+	if tally := img.WeightedNRGBAAt(1, 1).Tally; tally != 0.0 {
+		t.Fatalf("expected a tally of 0 but saw %v", tally)
+	}
+	for _, sigma := range []float64{0.0, -1.0, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected GaussianKernel(%v) to panic", sigma)
+				}
+			}()
+			GaussianKernel(sigma)
+		}()
+	}
+}